@@ -0,0 +1,63 @@
+package main
+
+// ScheduleAffinity 提交一个带亲和性key的任务，尽量让相同key的任务由同一个worker执行，以利用worker上已预热的缓存
+// 上一个相同key的任务所在的worker此时空闲时直接交给它，否则与Schedule相同交给任意worker，之后的任务改为亲近实际执行它的worker
+// 亲和性只是尽力而为，不保证相同key的任务总在同一个worker上执行，也不保证它们串行执行；需要串行执行时应使用ScheduleKeyed
+// 记录的key在Reset时清除
+func (p *Pool) ScheduleAffinity(key string, t Task) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.synchronous {
+		return p.runInline(t)
+	}
+
+	task := func() {
+		p.bindAffinity(key)
+		t()
+	}
+	if p.tryAffinity(key, task) {
+		return nil
+	}
+	return p.Schedule(task)
+}
+
+// tryAffinity 非阻塞地将任务交给key对应的worker，该worker不存在或不空闲时返回false
+func (p *Pool) tryAffinity(key string, t Task) bool {
+	p.affinityMu.Lock()
+	ws := p.affinity[key]
+	p.affinityMu.Unlock()
+	if ws == nil {
+		return false
+	}
+
+	if err := p.enterSchedule(); err != nil {
+		return false
+	}
+	defer p.exitSchedule()
+	t = p.afterBarrier(t)
+
+	select {
+	case ws.inbox <- t:
+		p.accepted()
+		return true
+	default:
+		p.donePending()
+		return false
+	}
+}
+
+// bindAffinity 在任务开始执行时调用，将key关联到当前worker
+func (p *Pool) bindAffinity(key string) {
+	ws := p.currentWorker()
+	if ws == nil {
+		return
+	}
+
+	p.affinityMu.Lock()
+	if p.affinity == nil {
+		p.affinity = make(map[string]*workerState)
+	}
+	p.affinity[key] = ws
+	p.affinityMu.Unlock()
+}
@@ -0,0 +1,126 @@
+package main
+
+import "sync"
+
+// barrier 记录一次Barrier调用的状态
+type barrier struct {
+	installed chan struct{} // 屏障生效后关闭，此后提交的任务都会等待屏障打开
+	done      chan struct{} // 屏障打开后关闭
+	openOnce  sync.Once
+}
+
+// open 打开屏障，让等待中的任务开始执行，可以重复调用
+func (b *barrier) open() {
+	b.openOnce.Do(func() { close(b.done) })
+}
+
+// Barrier 在任务队列中插入一个同步点，阻塞等待调用前已提交的任务全部执行完成
+// 等待期间工作池继续接收新任务，但这些任务在屏障之前的任务全部完成后才会开始执行
+// 与Flush相同，优先级任务、延迟任务不在屏障的约束范围内；与Barrier并发提交的任务不保证位于屏障的哪一侧
+// 多个Barrier调用依次进行；在worker中调用会因等待自身而死锁，此时返回ErrWouldDeadlock；与Flush相同，设置了WithQueue时返回ErrUnorderedQueue
+// 工作池被释放时返回ErrWorkerPoolFreed，此时等待屏障的任务不再受约束；提交屏障任务失败时返回提交错误
+func (p *Pool) Barrier() error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.inWorker() {
+		return ErrWouldDeadlock
+	}
+	if p.custom != nil {
+		return ErrUnorderedQueue
+	}
+
+	p.barrierMu.Lock()
+	defer p.barrierMu.Unlock()
+
+	b := &barrier{installed: make(chan struct{}), done: make(chan struct{})}
+	defer b.open()
+
+	// 屏障任务本身不受屏障约束，先提交再让屏障生效
+	reached := make(chan struct{})
+	if err := p.Schedule(func() {
+		p.runBarrier(b)
+		close(reached)
+	}); err != nil {
+		return err
+	}
+
+	p.lockAccept()
+	p.barrier = b
+	p.unlockAccept()
+	close(b.installed)
+
+	defer func() {
+		p.lockAccept()
+		p.barrier = nil
+		p.unlockAccept()
+	}()
+
+	select {
+	case <-reached:
+		return nil
+	case <-p.quit:
+		return ErrWorkerPoolFreed
+	}
+}
+
+// runBarrier 在worker上执行屏障任务，此时屏障之前的任务均已被worker取走
+// 等待其他每个worker完成手头屏障之前的任务后打开屏障；等待屏障打开的任务会代替所在的worker确认
+func (p *Pool) runBarrier(b *barrier) {
+	<-b.installed
+
+	self := p.currentWorker()
+	p.statesMu.Lock()
+	states := make([]*workerState, 0, len(p.states))
+	for _, ws := range p.states {
+		if ws != self {
+			states = append(states, ws)
+		}
+	}
+	p.statesMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ws := range states {
+		ws.addFlush(&wg)
+	}
+	wg.Wait()
+
+	b.open()
+}
+
+// afterBarrier 有进行中的Barrier时包装新提交的任务，使其在屏障打开后才开始执行，调用方需持有acceptMu的读锁
+// 等待期间所在的worker没有执行屏障之前的任务，代替worker确认屏障的等待
+func (p *Pool) afterBarrier(t Task) Task {
+	b := p.barrier
+	if b == nil {
+		return t
+	}
+
+	var w Task
+	w = func() {
+		p.cancelDrop(w)
+
+		var signal chan struct{}
+		ws := p.currentWorker()
+		if ws != nil {
+			signal = ws.signal
+		}
+
+	wait:
+		for {
+			select {
+			case <-b.done:
+				break wait
+			case <-signal:
+				ws.ackFlush()
+			case <-p.quit:
+				break wait
+			}
+		}
+
+		t()
+	}
+	// 包装后进入任务队列的是w，t登记的丢弃回调转由w触发
+	p.moveDrop(t, w)
+	return w
+}
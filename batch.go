@@ -0,0 +1,34 @@
+package main
+
+import "sync/atomic"
+
+// Batch 表示一批通过同一个工作池执行、可以统一取消的相关任务
+// 与ScheduleCancelable相同，被取消的任务仍会占用队列中的位置，直到worker取出它后直接跳过
+type Batch struct {
+	pool     *Pool
+	canceled atomic.Bool
+}
+
+// NewBatch 创建一个使用当前工作池执行任务的批次
+func (p *Pool) NewBatch() *Batch {
+	return &Batch{pool: p}
+}
+
+// Schedule 通过工作池提交一个属于该批次的任务，批次已被取消时任务不会执行
+func (b *Batch) Schedule(t Task) error {
+	return b.pool.Schedule(func() {
+		if !b.canceled.Load() {
+			t()
+		}
+	})
+}
+
+// Cancel 取消批次中所有尚未开始执行的任务，已经开始执行的任务不受影响，之后提交到该批次的任务也不会执行
+func (b *Batch) Cancel() {
+	b.canceled.Store(true)
+}
+
+// Canceled 返回批次是否已被取消
+func (b *Batch) Canceled() bool {
+	return b.canceled.Load()
+}
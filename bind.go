@@ -0,0 +1,22 @@
+package main
+
+// Bind 将fn与参数arg绑定为一个任务，arg在调用Bind时求值，避免在循环中提交闭包时误捕获循环变量
+func Bind[T any](fn func(T), arg T) Task {
+	return func() { fn(arg) }
+}
+
+// Bind2 将fn与参数a、b绑定为一个任务，参数在调用Bind2时求值
+func Bind2[T, U any](fn func(T, U), a T, b U) Task {
+	return func() { fn(a, b) }
+}
+
+// ScheduleFn 将fn与参数arg绑定后提交到工作池执行，等价于p.Schedule(Bind(fn, arg))
+// Go的方法不能带类型参数，因此以函数的形式提供
+func ScheduleFn[T any](p *Pool, fn func(T), arg T) error {
+	return p.Schedule(Bind(fn, arg))
+}
+
+// ScheduleFn2 将fn与参数a、b绑定后提交到工作池执行，等价于p.Schedule(Bind2(fn, a, b))
+func ScheduleFn2[T, U any](p *Pool, fn func(T, U), a T, b U) error {
+	return p.Schedule(Bind2(fn, a, b))
+}
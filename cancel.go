@@ -0,0 +1,30 @@
+package main
+
+import "sync/atomic"
+
+// 可取消任务的状态
+const (
+	taskWaiting  int32 = iota // 等待执行
+	taskStarted               // 已开始执行
+	taskCanceled              // 已被取消
+)
+
+// ScheduleCancelable 提交一个可以在开始执行前取消的任务，返回用于取消该任务的函数
+// 任务尚未开始执行时cancel返回true，此后任务不会再执行；任务已经开始执行或已被取消时返回false
+// 被取消的任务仍会占用队列中的位置，直到worker取出它后直接跳过
+func (p *Pool) ScheduleCancelable(t Task) (cancel func() bool, err error) {
+	var state atomic.Int32
+
+	err = p.Schedule(func() {
+		if state.CompareAndSwap(taskWaiting, taskStarted) {
+			t()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() bool {
+		return state.CompareAndSwap(taskWaiting, taskCanceled)
+	}, nil
+}
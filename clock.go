@@ -0,0 +1,51 @@
+package main
+
+import "time"
+
+// Clock 定义工作池使用的时钟，空闲超时、延迟任务、限流、超时等待等基于时间的逻辑都通过它计时
+// 默认使用系统时钟，测试中可以通过WithClock替换为可手动推进的时钟
+type Clock interface {
+	Now() time.Time                            // 返回当前时间
+	After(d time.Duration) <-chan time.Time    // 返回d之后接收到当前时间的通道
+	NewTimer(d time.Duration) Timer            // 创建d之后触发的定时器
+	AfterFunc(d time.Duration, f func()) Timer // 创建d之后在独立协程中调用f的定时器
+}
+
+// Timer 定义Clock创建的定时器，语义与time.Timer一致
+type Timer interface {
+	C() <-chan time.Time        // 定时器触发时接收时间的通道，AfterFunc创建的定时器返回nil
+	Stop() bool                 // 停止定时器，定时器已触发或已停止时返回false
+	Reset(d time.Duration) bool // 重新设置定时器在d之后触发
+}
+
+// WithClock 设置工作池使用的时钟的选项，c为nil时使用系统时钟
+// 通过ctx传递的截止时间（如WithTaskTimeout生成的ctx）仍由context包基于系统时钟计时
+func WithClock(c Clock) Option {
+	return func(p *Pool) {
+		if c != nil {
+			p.clock = c
+		}
+	}
+}
+
+// realClock 基于time包实现的系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return realTimer{time.AfterFunc(d, f)} }
+
+// realTimer 包装time.Timer以实现Timer接口
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+
+func (r realTimer) Stop() bool { return r.t.Stop() }
+
+func (r realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
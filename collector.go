@@ -0,0 +1,83 @@
+package main
+
+import "sync"
+
+// Collector 通过工作池并发执行一组返回结果的任务并收集结果，尚未被取走的结果数量有上限，避免扇出大量任务时占用过多内存
+type Collector[R any] struct {
+	pool    *Pool
+	slots   chan struct{} // 正在执行的任务占用的名额
+	results chan R        // 已完成但尚未被取走的结果
+	tasks   taskCounter
+	once    sync.Once
+	err     error // 关闭results前设置，工作池被释放导致部分任务被丢弃时为ErrWorkerPoolFreed
+}
+
+// NewCollector 创建一个使用p执行任务的Collector，limit为正在执行和等待被取走的结果数量各自的上限，不大于0时为1
+// 结果积压到上限后worker会阻塞在发送结果上，Submit随之阻塞，调用方需要在提交的同时通过ResultsChan消费结果，
+// 或者保证提交的任务数量不超过limit后再调用Results
+func NewCollector[R any](p *Pool, limit int) *Collector[R] {
+	limit = max(limit, 1)
+
+	return &Collector[R]{
+		pool:    p,
+		slots:   make(chan struct{}, limit),
+		results: make(chan R, limit),
+	}
+}
+
+// Submit 提交一个返回结果的任务，正在执行的任务数量达到上限时阻塞等待
+// 任务panic时没有结果，panic按工作池的方式处理；提交失败时返回提交错误，等待名额期间工作池开始释放时返回ErrWorkerPoolFreed
+func (c *Collector[R]) Submit(fn func() R) error {
+	if !c.pool.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	case <-c.pool.quit:
+		return ErrWorkerPoolFreed
+	}
+	c.tasks.add()
+
+	err := c.pool.Schedule(func() {
+		defer c.tasks.done()
+		defer func() { <-c.slots }()
+
+		c.results <- fn()
+	})
+	if err != nil {
+		c.tasks.done()
+		<-c.slots
+		return err
+	}
+
+	return nil
+}
+
+// Close 在所有任务提交完成后调用，等待已提交的任务全部执行完成后关闭ResultsChan返回的通道
+// Close可以重复调用，之后不能再调用Submit；工作池被Free释放、排队的任务被丢弃时，释放完成后同样关闭该通道
+func (c *Collector[R]) Close() {
+	c.once.Do(func() {
+		go func() {
+			c.err = c.pool.awaitTasks(c.tasks.wait())
+			close(c.results)
+		}()
+	})
+}
+
+// ResultsChan 返回按完成顺序传递结果的通道，调用Close并且所有任务执行完成后该通道被关闭
+func (c *Collector[R]) ResultsChan() <-chan R {
+	return c.results
+}
+
+// Results 在所有任务提交完成后调用，等待任务全部执行完成并按完成顺序返回所有结果，会隐式调用Close
+// 工作池被Free释放、部分任务被丢弃时返回已收集到的结果和ErrWorkerPoolFreed
+func (c *Collector[R]) Results() ([]R, error) {
+	c.Close()
+
+	var results []R
+	for r := range c.results {
+		results = append(results, r)
+	}
+	return results, c.err
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ContextTask 定义可感知取消的任务类型，任务应当在ctx结束后尽快返回
+type ContextTask func(ctx context.Context)
+
+// WithTaskTimeout 设置任务执行超时时间的选项，仅对通过ScheduleCtx提交的任务生效
+// 超时后传入任务的ctx会被取消，但工作池无法强制终止不检查ctx的任务，这类任务会一直占用worker直到自行返回
+func WithTaskTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.taskTimeout = d
+	}
+}
+
+// WithContextPropagation 设置通过ScheduleCtx提交的任务只从调用方ctx中传递指定值的选项，默认传递整个调用方ctx
+// 设置后在提交时从调用方ctx中取出keys对应的值，任务的ctx基于一个新的ctx携带这些值（例如trace ID），
+// 不再继承调用方ctx的取消，调用方的请求结束后任务仍可继续执行；调用方ctx中不存在的key不会被传递
+func WithContextPropagation(keys ...any) Option {
+	return func(p *Pool) {
+		p.propagateKeys = append([]any(nil), keys...)
+	}
+}
+
+// ScheduleCtx 提交一个可感知取消的任务到工作池执行，调用方的ctx会一直传递到任务中
+// 等待任务被接收期间ctx结束时返回ctx.Err()，与ScheduleWithContext相同
+// 任务开始执行时基于ctx生成任务的ctx，配置了WithTaskTimeout时在超时后被取消，任务返回后同样会被取消
+// 配置了WithContextPropagation时任务的ctx只携带提交时从ctx中取出的指定值
+func (p *Pool) ScheduleCtx(ctx context.Context, fn ContextTask) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	parent := p.propagate(ctx)
+	return p.ScheduleWithContext(ctx, func() {
+		ctx, cancel := p.taskContext(parent)
+		defer cancel()
+
+		fn(ctx)
+	})
+}
+
+// propagate 在提交时取出ctx中需要传递的值，返回携带这些值的新ctx，未配置WithContextPropagation时直接返回ctx
+func (p *Pool) propagate(ctx context.Context) context.Context {
+	if len(p.propagateKeys) == 0 {
+		return ctx
+	}
+
+	parent := context.Background()
+	for _, key := range p.propagateKeys {
+		if v := ctx.Value(key); v != nil {
+			parent = context.WithValue(parent, key, v)
+		}
+	}
+	return parent
+}
+
+// taskContext 基于parent为即将执行的任务生成ctx
+func (p *Pool) taskContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if p.taskTimeout > 0 {
+		return context.WithTimeout(parent, p.taskTimeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// SubmitTimeout 提交一个可感知取消的任务并等待其执行完成，同时限制等待接收和执行的时间
+// enqueue时间内任务没有被接收时返回ErrScheduleTimeout；任务开始执行后传入的ctx在exec之后被取消，
+// 此时任务仍未返回则返回ErrTaskTimeout，任务会在worker上继续运行直到自行返回
+// 任务正常返回时返回nil，任务panic时返回包装了ErrTaskPanic的错误，任务开始执行前工作池被释放时返回ErrWorkerPoolFreed
+func (p *Pool) SubmitTimeout(fn ContextTask, enqueue, exec time.Duration) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	started := make(chan context.Context, 1)
+	done := make(chan error, 1)
+
+	err := p.ScheduleTimeout(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), exec)
+		defer cancel()
+
+		started <- ctx
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%w: %v", ErrTaskPanic, r)
+				return
+			}
+			done <- nil
+		}()
+
+		fn(ctx)
+	}, enqueue)
+	if err != nil {
+		return err
+	}
+
+	// 工作池在任务开始执行前被释放时，任务可能被丢弃而不再执行
+	var ctx context.Context
+	select {
+	case ctx = <-started:
+	case <-p.quit:
+		return ErrWorkerPoolFreed
+	}
+
+	select {
+	case err := <-done:
+		// 任务因ctx超时而返回时同样视为超时
+		if err == nil && ctx.Err() == context.DeadlineExceeded {
+			return ErrTaskTimeout
+		}
+		return err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrTaskTimeout
+		}
+		// ctx在任务返回后被取消，此时结果已经可用
+		return <-done
+	}
+}
+
+// ScheduleJoin 将tasks全部提交到工作池执行，阻塞到第一个任务正常返回，返回它在tasks中的下标，用于同时请求多个副本并采用最快结果的场景
+// 所有任务共享同一个ctx，第一个任务正常返回时在任务内部取消ctx，通知其余任务尽快返回；不检查ctx的任务会继续执行到自行返回
+// ctx被取消后仍在等待提交的任务视为落败，不再提交，因此工作池饱和时第一个任务完成即可返回，不必等待全部任务提交
+// 任务的签名为ContextTask以便接收取消通知；panic的任务不会胜出，全部任务都panic时返回第一个包装了ErrTaskPanic的错误
+// 提交某个任务失败时取消已提交的任务并返回提交错误；工作池在任务完成前被释放时返回ErrWorkerPoolFreed；tasks为空时返回-1和nil
+func (p *Pool) ScheduleJoin(tasks []ContextTask) (winner int, err error) {
+	if len(tasks) == 0 {
+		return -1, nil
+	}
+
+	shared, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 缓冲区容纳全部结果，落败的任务返回时不会阻塞
+	type result struct {
+		index int
+		err   error
+	}
+	results := make(chan result, len(tasks))
+
+	submitted := 0
+	for i, fn := range tasks {
+		err := p.ScheduleWithContext(shared, func() {
+			ctx, done := p.taskContext(shared)
+			defer done()
+			defer func() {
+				if r := recover(); r != nil {
+					results <- result{i, fmt.Errorf("%w: %v", ErrTaskPanic, r)}
+					return
+				}
+				cancel()
+				results <- result{i, nil}
+			}()
+
+			fn(ctx)
+		})
+		if err != nil {
+			// shared已被取消说明已有任务胜出，剩余任务落败，无需提交
+			if shared.Err() != nil {
+				break
+			}
+			return -1, err
+		}
+		submitted++
+	}
+
+	var first error
+	for i := 0; i < submitted; i++ {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.index, nil
+			}
+			if first == nil {
+				first = r.err
+			}
+		case <-p.quit:
+			return -1, ErrWorkerPoolFreed
+		}
+	}
+	return -1, first
+}
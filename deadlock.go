@@ -0,0 +1,77 @@
+package main
+
+import "time"
+
+// WithDeadlockDetection 设置死锁检测的选项，默认不检测
+// 开启后后台协程定期检查，所有worker都在执行任务、有任务在等待且持续d时间没有任何任务完成时，输出警告日志
+// 每次停滞只告警一次，有任务完成后重新开始计时；检测只用于诊断，不会中断任务
+func WithDeadlockDetection(d time.Duration) Option {
+	return func(p *Pool) {
+		p.deadlockTimeout = d
+	}
+}
+
+// WithDeadlockCallback 设置死锁检测告警时调用的回调的选项，需要同时设置WithDeadlockDetection才会生效
+// 回调与告警日志一样每次停滞只调用一次，自身的panic会被捕获并记录日志
+func WithDeadlockCallback(onDeadlock func()) Option {
+	return func(p *Pool) {
+		p.onDeadlock = onDeadlock
+	}
+}
+
+// watchDeadlock 死锁检测协程主循环，以d的四分之一为间隔检查工作池是否停滞
+func (p *Pool) watchDeadlock() {
+	defer p.wg.Done()
+
+	d := p.deadlockTimeout
+	timer := p.clock.NewTimer(d / 4)
+	defer timer.Stop()
+
+	var (
+		stuckSince time.Time // 开始停滞的时间，未停滞时为零值
+		completed  uint64    // 开始停滞时累计完成的任务数量
+		warned     bool      // 本次停滞是否已经告警
+	)
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-timer.C():
+		}
+		timer.Reset(d / 4)
+
+		now := p.clock.Now()
+		if !p.stalled() || p.completed.Load() != completed {
+			stuckSince, completed, warned = time.Time{}, p.completed.Load(), false
+			continue
+		}
+		if stuckSince.IsZero() {
+			stuckSince = now
+			continue
+		}
+		if warned || now.Sub(stuckSince) < d {
+			continue
+		}
+
+		warned = true
+		p.logf("WARNING: possible deadlock, all %d workers busy with %d tasks waiting and no task completed in %v\n",
+			p.Running(), p.waiting(), now.Sub(stuckSince))
+		if p.onDeadlock != nil {
+			p.callHook(0, p.onDeadlock)
+		}
+	}
+}
+
+// stalled 返回工作池是否处于疑似停滞的状态：所有容量都在执行任务且有任务在等待
+func (p *Pool) stalled() bool {
+	return p.Running() >= p.Cap() && p.waiting() > 0
+}
+
+// waiting 返回已提交但尚未开始执行的任务数量，包括排队中的任务和阻塞在提交中的调用方
+func (p *Pool) waiting() int {
+	p.pendingMu.Lock()
+	n := p.pending
+	p.pendingMu.Unlock()
+
+	return int(n) - p.Running()
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DebugHandler 返回以JSON格式输出工作池当前运行指标的http.Handler，可以挂载到任意路由上用于在线查看
+// 输出内容为Stats返回的PoolStats，读取指标不会影响工作池的运行
+func (p *Pool) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p.Stats()); err != nil {
+			p.logf("debug handler: %v\n", err)
+		}
+	})
+}
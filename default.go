@@ -0,0 +1,26 @@
+package main
+
+import "sync"
+
+var (
+	defaultPool     *Pool     // 包级默认工作池
+	defaultPoolOnce sync.Once // 保证默认工作池只被初始化一次
+)
+
+// DefaultPool 返回包级默认工作池，首次调用时以默认容量创建
+func DefaultPool() *Pool {
+	defaultPoolOnce.Do(func() {
+		defaultPool = New(defaultCapacity)
+	})
+	return defaultPool
+}
+
+// Submit 提交一个任务到默认工作池执行
+func Submit(t Task) error {
+	return DefaultPool().Schedule(t)
+}
+
+// Shutdown 优雅关闭默认工作池
+func Shutdown() {
+	DefaultPool().Shutdown()
+}
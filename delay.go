@@ -0,0 +1,44 @@
+package main
+
+import "time"
+
+// ScheduleAfter 在延迟d之后将任务提交到工作池执行，不会阻塞提交方
+// 工作池在延迟到期前被释放时，等待中的定时器会被取消，任务不会执行
+func (p *Pool) ScheduleAfter(t Task, d time.Duration) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	p.timersMu.Lock()
+	defer p.timersMu.Unlock()
+
+	// 在持有锁的情况下检查，保证释放时不会遗漏刚注册的定时器
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+
+	var timer Timer
+	timer = p.clock.AfterFunc(d, func() {
+		p.timersMu.Lock()
+		delete(p.timers, timer)
+		p.timersMu.Unlock()
+
+		if err := p.Schedule(t); err != nil {
+			p.logf("delayed task dropped: %v\n", err)
+		}
+	})
+	p.timers[timer] = struct{}{}
+
+	return nil
+}
+
+// stopTimers 取消所有尚未到期的延迟任务
+func (p *Pool) stopTimers() {
+	p.timersMu.Lock()
+	defer p.timersMu.Unlock()
+
+	for timer := range p.timers {
+		timer.Stop()
+		delete(p.timers, timer)
+	}
+}
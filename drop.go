@@ -0,0 +1,53 @@
+package main
+
+import "unsafe"
+
+// taskID 返回任务闭包对象的地址，Task无法比较，只能以此识别同一个任务
+// 登记期间任务被任务队列或调用方引用，不会被回收，地址也就不会被其他任务复用
+func taskID(t Task) uintptr {
+	return uintptr(*(*unsafe.Pointer)(unsafe.Pointer(&t)))
+}
+
+// onDrop 登记t在任务队列中被PolicyDropOldest丢弃时调用的回调，其他策略下不会丢弃任务，不做登记
+// 用于持有内部状态的包装任务，例如key和Limiter的任务链；t开始执行时需调用cancelDrop取消登记
+func (p *Pool) onDrop(t Task, fn func()) {
+	if p.queueFullPolicy != PolicyDropOldest {
+		return
+	}
+	p.dropHooks.Store(taskID(t), fn)
+}
+
+// cancelDrop 取消t的丢弃回调，在t开始执行或提交失败时调用
+func (p *Pool) cancelDrop(t Task) {
+	if p.queueFullPolicy != PolicyDropOldest {
+		return
+	}
+	p.dropHooks.Delete(taskID(t))
+}
+
+// moveDrop 将from的丢弃回调转移给包装了from的to，to进入任务队列后被丢弃时同样调用
+func (p *Pool) moveDrop(from, to Task) {
+	if p.queueFullPolicy != PolicyDropOldest {
+		return
+	}
+	if fn, ok := p.dropHooks.LoadAndDelete(taskID(from)); ok {
+		p.dropHooks.Store(taskID(to), fn)
+	}
+}
+
+// dropped 在t被丢弃后调用其丢弃回调，调用方持有acceptMu的读锁
+func (p *Pool) dropped(t Task) {
+	if fn, ok := p.dropHooks.LoadAndDelete(taskID(t)); ok {
+		fn.(func())()
+	}
+}
+
+// discarding 返回工作池是否已开始非优雅的释放，此时尚未开始执行的任务应当被丢弃
+func (p *Pool) discarding() bool {
+	select {
+	case <-p.quit:
+		return !p.graceful.Load()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "time"
+
+// EventType 定义工作池生命周期事件的类型
+type EventType int
+
+const (
+	EventWorkerStart  EventType = iota // worker启动
+	EventWorkerExit                    // worker退出，包括关闭、空闲超时和缩容退出
+	EventTaskReceived                  // worker接收到一个任务，即将执行
+	EventTaskPanic                     // 任务执行过程中发生panic
+)
+
+// String 返回事件类型的可读名称
+func (e EventType) String() string {
+	switch e {
+	case EventWorkerStart:
+		return "worker-start"
+	case EventWorkerExit:
+		return "worker-exit"
+	case EventTaskReceived:
+		return "task-received"
+	case EventTaskPanic:
+		return "task-panic"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 工作池生命周期事件
+type Event struct {
+	Type     EventType // 事件类型
+	WorkerID int       // 产生事件的worker编号
+	Time     time.Time // 事件发生的时间
+	Panic    any       // EventTaskPanic事件中任务panic的值，其余事件为nil
+}
+
+// WithEventChannel 设置接收工作池生命周期事件的通道的选项
+// 事件以非阻塞方式发送，通道已满时直接丢弃，不会拖慢worker；Free返回后不会再发送事件
+func WithEventChannel(ch chan<- Event) Option {
+	return func(p *Pool) {
+		p.events = ch
+	}
+}
+
+// emit 非阻塞地发送一个事件，没有配置事件通道时直接返回
+func (p *Pool) emit(typ EventType, id int, v any) {
+	if p.events == nil {
+		return
+	}
+
+	select {
+	case p.events <- Event{Type: typ, WorkerID: id, Time: p.clock.Now(), Panic: v}:
+	default:
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WithFairness 设置优先级任务按权重轮转分发的选项，默认按优先级严格从高到低分发
+// weights的键为优先级，值为该优先级在一轮分发中最多连续分发的任务数量，未出现在weights中的优先级权重为1
+// 分发协程从高到低轮流服务每个有等待任务的优先级，低优先级的任务不会因为高优先级任务持续提交而饿死
+// 同一优先级内按提交顺序分发；权重必须大于0，否则NewWithError返回ErrInvalidOption
+func WithFairness(weights map[int]int) Option {
+	return func(p *Pool) {
+		p.fair = newFairQueue(weights)
+	}
+}
+
+// fairQueue 按权重轮转的优先级队列，每个优先级拥有独立的先进先出队列
+type fairQueue struct {
+	weights map[int]int             // 每个优先级的权重
+	classes []int                   // 有过任务的优先级，从高到低排列
+	queues  map[int][]*priorityItem // 每个优先级等待分发的任务
+	n       int                     // 等待分发的任务总数
+
+	started bool // 是否已开始轮转，首次分发从最高的优先级开始
+	cur     int  // 当前正在服务的优先级
+	credit  int  // 当前优先级在本轮中剩余可分发的任务数量
+
+	prev fairCursor // 最近一次pop之前的轮转状态，用于unpop恢复
+}
+
+// fairCursor 记录fairQueue的轮转状态
+type fairCursor struct {
+	started bool
+	cur     int
+	credit  int
+}
+
+// newFairQueue 创建按weights轮转的优先级队列
+func newFairQueue(weights map[int]int) *fairQueue {
+	w := make(map[int]int, len(weights))
+	for k, v := range weights {
+		w[k] = v
+	}
+	return &fairQueue{weights: w, queues: make(map[int][]*priorityItem)}
+}
+
+// validate 检查权重是否合法
+func (q *fairQueue) validate() error {
+	for priority, w := range q.weights {
+		if w <= 0 {
+			return fmt.Errorf("%w: fairness weight %d for priority %d", ErrInvalidOption, w, priority)
+		}
+	}
+	return nil
+}
+
+// weight 返回优先级的权重，通过New创建时非法的权重按1处理
+func (q *fairQueue) weight(priority int) int {
+	if w, ok := q.weights[priority]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// push 将任务加入所属优先级队列的末尾
+func (q *fairQueue) push(item *priorityItem) {
+	if _, ok := q.queues[item.priority]; !ok {
+		i := sort.Search(len(q.classes), func(i int) bool { return q.classes[i] <= item.priority })
+		q.classes = append(q.classes, 0)
+		copy(q.classes[i+1:], q.classes[i:])
+		q.classes[i] = item.priority
+	}
+	q.queues[item.priority] = append(q.queues[item.priority], item)
+	q.n++
+}
+
+// next 返回比priority低的下一个优先级，已是最低时回到最高的优先级
+func (q *fairQueue) next(priority int) int {
+	i := sort.Search(len(q.classes), func(i int) bool { return q.classes[i] < priority })
+	if i == len(q.classes) {
+		return q.classes[0]
+	}
+	return q.classes[i]
+}
+
+// pop 按权重轮转取出下一个要分发的任务
+func (q *fairQueue) pop() (*priorityItem, bool) {
+	if q.n == 0 {
+		return nil, false
+	}
+	q.prev = fairCursor{started: q.started, cur: q.cur, credit: q.credit}
+
+	if !q.started {
+		q.started = true
+		q.cur = q.classes[0]
+		q.credit = q.weight(q.cur)
+	}
+
+	// 当前优先级的额度用完或没有等待的任务时轮到下一个优先级
+	if q.credit == 0 || len(q.queues[q.cur]) == 0 {
+		q.cur = q.next(q.cur)
+		for len(q.queues[q.cur]) == 0 {
+			q.cur = q.next(q.cur)
+		}
+		q.credit = q.weight(q.cur)
+	}
+
+	items := q.queues[q.cur]
+	item := items[0]
+	items[0] = nil
+	q.queues[q.cur] = items[1:]
+	q.n--
+	q.credit--
+	return item, true
+}
+
+// unpop 将最近一次pop取出但未能分发的任务放回队首，并恢复轮转状态
+func (q *fairQueue) unpop(item *priorityItem) {
+	q.queues[item.priority] = append([]*priorityItem{item}, q.queues[item.priority]...)
+	q.n++
+	q.started, q.cur, q.credit = q.prev.started, q.prev.cur, q.prev.credit
+}
+
+// len 返回等待分发的任务数量
+func (q *fairQueue) len() int {
+	return q.n
+}
+
+// reset 丢弃所有等待分发的任务并重置轮转状态
+func (q *fairQueue) reset() {
+	*q = fairQueue{weights: q.weights, queues: make(map[int][]*priorityItem)}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// workerState 记录单个存活worker的统计信息，并用于Flush与该worker同步
+type workerState struct {
+	id      int           // worker的编号
+	handled atomic.Uint64 // 累计执行完成的任务数量
+
+	mu      sync.Mutex
+	exited  bool              // worker是否已退出，退出后Flush不再等待它
+	flushes []*sync.WaitGroup // 等待该worker完成当前任务的Flush调用
+	signal  chan struct{}     // 通知worker有新的Flush在等待
+	inbox   chan Task         // 只发给该worker的任务，用于ScheduleAffinity，worker空闲时才能接收
+}
+
+// newWorkerState 创建worker的状态
+func newWorkerState(id int) *workerState {
+	return &workerState{id: id, signal: make(chan struct{}, 1), inbox: make(chan Task)}
+}
+
+// count 将worker完成的任务数量增加n，返回是否达到了退出前最多执行的任务数量limit，limit为0时不限制
+// 接续执行的任务可能使数量一次越过limit，因此按不小于判断
+func (ws *workerState) count(n, limit uint64) bool {
+	return ws.handled.Add(n) >= limit && limit > 0
+}
+
+// addFlush 让worker在完成当前任务后通知wg，worker已退出时返回false
+func (ws *workerState) addFlush(wg *sync.WaitGroup) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.exited {
+		return false
+	}
+	wg.Add(1)
+	ws.flushes = append(ws.flushes, wg)
+
+	select {
+	case ws.signal <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// ackFlush 在worker没有执行任务时调用，通知所有等待它的Flush
+func (ws *workerState) ackFlush() {
+	ws.mu.Lock()
+	flushes := ws.flushes
+	ws.flushes = nil
+	ws.mu.Unlock()
+
+	for _, wg := range flushes {
+		wg.Done()
+	}
+}
+
+// exit 在worker退出时调用，通知所有等待它的Flush，此后不再接收新的Flush
+func (ws *workerState) exit() {
+	ws.mu.Lock()
+	ws.exited = true
+	ws.mu.Unlock()
+
+	ws.ackFlush()
+}
+
+// Flush 阻塞等待调用时已通过任务队列提交的任务全部执行完成，调用之后提交的任务不在等待范围内
+// 生产者持续提交任务时WaitIdle可能一直无法返回，Flush则只等待调用时已提交的这一批任务
+// 实现上先提交一个哨兵任务，哨兵被执行时之前的任务均已被worker取走，再等待每个存活的worker完成手头的任务
+// 优先级任务、延迟任务不在等待范围内；使用PolicyDropOldest时哨兵任务可能被丢弃，此时Flush会一直等到工作池被释放
+// 哨兵任务依赖任务队列先进先出，设置了WithQueue时返回ErrUnorderedQueue；在worker中调用会因等待自身而死锁，此时返回ErrWouldDeadlock
+// 工作池被释放时返回ErrWorkerPoolFreed，提交哨兵任务失败时返回提交错误
+func (p *Pool) Flush() error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.inWorker() {
+		return ErrWouldDeadlock
+	}
+	if p.custom != nil {
+		return ErrUnorderedQueue
+	}
+
+	reached := make(chan struct{})
+	if err := p.Schedule(func() { close(reached) }); err != nil {
+		return err
+	}
+
+	select {
+	case <-reached:
+	case <-p.quit:
+		return ErrWorkerPoolFreed
+	}
+
+	p.statesMu.Lock()
+	states := make([]*workerState, 0, len(p.states))
+	for _, ws := range p.states {
+		states = append(states, ws)
+	}
+	p.statesMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ws := range states {
+		ws.addFlush(&wg)
+	}
+	wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+// SubmitAndForget 提交一个任务且不阻塞、不因容量不足丢弃，适用于不关心提交结果的调用方
+// 没有空闲worker且任务队列已满时，任务进入无上限的溢出缓冲区，由后台协程在有容量时按顺序交给worker；
+// 溢出缓冲区中已有任务时新任务同样排在其后，以空间换取不阻塞，可通过OverflowLen监控其长度
+// 工作池未接收任务（未初始化、已释放或正在排空）时任务被丢弃并记录日志；同步模式下在调用方协程中直接执行
+// 工作池被释放时，溢出缓冲区中的任务在优雅关闭时执行，否则被丢弃
+func (p *Pool) SubmitAndForget(t Task) {
+	if !p.isInitialized() {
+		return
+	}
+	if p.synchronous {
+		_ = p.runInline(t)
+		return
+	}
+
+	if err := p.enterSchedule(); err != nil {
+		p.logf("workerpool forget task: %v\n", err)
+		return
+	}
+	defer p.exitSchedule()
+	t = p.afterBarrier(t)
+
+	if p.custom != nil {
+		p.pushQueue(t)
+		return
+	}
+
+	p.spillMu.Lock()
+	defer p.spillMu.Unlock()
+
+	if !p.spilling {
+		select {
+		case p.taskChan() <- t:
+			p.accepted()
+			return
+		case p.spawn <- t:
+			p.accepted()
+			return
+		default:
+		}
+
+		// 持有读锁时计入wg，保证Free等待后台协程退出
+		p.spilling = true
+		p.wg.Add(1)
+		go p.drainSpill()
+	}
+
+	p.spill = append(p.spill, t)
+	p.accepted()
+}
+
+// forward 将一个已计入未完成任务的任务放入溢出缓冲区，由后台协程交给worker，不会阻塞，调用方需持有acceptMu的读锁
+// 用于在无法阻塞的位置（例如丢弃回调中）重新交付内部的包装任务
+func (p *Pool) forward(t Task) {
+	p.spillMu.Lock()
+	defer p.spillMu.Unlock()
+
+	if !p.spilling {
+		p.spilling = true
+		p.wg.Add(1)
+		go p.drainSpill()
+	}
+	p.spill = append(p.spill, t)
+}
+
+// OverflowLen 返回SubmitAndForget的溢出缓冲区中等待交给worker的任务数量
+func (p *Pool) OverflowLen() int {
+	if !p.isInitialized() {
+		return 0
+	}
+
+	p.spillMu.Lock()
+	defer p.spillMu.Unlock()
+
+	return len(p.spill)
+}
+
+// drainSpill 按顺序将溢出缓冲区中的任务阻塞地交给worker，缓冲区为空时退出
+func (p *Pool) drainSpill() {
+	defer p.wg.Done()
+
+	for {
+		p.spillMu.Lock()
+		if len(p.spill) == 0 {
+			p.spill = nil
+			p.spilling = false
+			p.spillMu.Unlock()
+			return
+		}
+		t := p.spill[0]
+		p.spill[0] = nil
+		p.spill = p.spill[1:]
+		p.spillMu.Unlock()
+
+		p.deliverSpilled(t)
+	}
+}
+
+// deliverSpilled 阻塞地交付一个已计入未完成任务的溢出任务，工作池退出时若为优雅关闭则直接执行，否则丢弃
+func (p *Pool) deliverSpilled(t Task) {
+	// 与提交方一样持有读锁，避免任务队列在交付期间被ResizeQueue替换
+	p.acceptMu.RLock()
+	defer p.acceptMu.RUnlock()
+
+	quit := p.quit
+	for {
+		y := p.acceptYield()
+		select {
+		case p.taskChan() <- t:
+			p.updateHighWater()
+			p.notify()
+			return
+		case p.spawn <- t:
+			return
+		case <-p.quit:
+			if p.graceful.Load() {
+				p.execute(0, t)
+			} else {
+				p.donePending()
+			}
+			return
+		case <-y.preempt:
+			if !p.yieldAccept(y, quit) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Future 表示一个异步任务的执行结果
+type Future struct {
+	done  chan struct{} // 任务完成后关闭
+	value any           // 任务返回值
+	err   error         // 任务返回的错误，任务panic时为包装了ErrTaskPanic的错误
+	clock Clock         // 提交任务的工作池的时钟，用于GetTimeout计时
+}
+
+// Submit 提交一个带返回值的任务到工作池执行，返回用于获取结果的Future
+func (p *Pool) Submit(fn func() (any, error)) (*Future, error) {
+	if !p.isInitialized() {
+		return nil, ErrPoolNotInitialized
+	}
+
+	f := &Future{done: make(chan struct{}), clock: p.clock}
+
+	err := p.Schedule(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				f.err = fmt.Errorf("%w: %v", ErrTaskPanic, r)
+			}
+			close(f.done)
+		}()
+
+		f.value, f.err = fn()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Get 阻塞等待任务完成并返回其结果
+func (f *Future) Get() (any, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// GetWithContext 等待任务完成并返回其结果，ctx结束时提前返回ctx.Err()
+func (f *Future) GetWithContext(ctx context.Context) (any, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// GetTimeout 等待任务完成并返回其结果，超过d仍未完成时返回ErrResultTimeout
+// 超时只是放弃等待，任务仍在worker中继续执行，之后可以再次调用Get等方法获取结果
+func (f *Future) GetTimeout(d time.Duration) (any, error) {
+	timer := f.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil, ErrResultTimeout
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// ScheduleNotify 提交一个任务到工作池执行，返回的通道在任务结束后传递一次执行结果
+// 任务正常返回时传递nil，任务panic时传递包装了ErrTaskPanic的错误，提交失败时传递提交错误
+func (p *Pool) ScheduleNotify(t Task) <-chan error {
+	ch := make(chan error, 1)
+
+	err := p.Schedule(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- fmt.Errorf("%w: %v", ErrTaskPanic, r)
+				return
+			}
+			ch <- nil
+		}()
+
+		t()
+	})
+	if err != nil {
+		ch <- err
+	}
+
+	return ch
+}
+
+// Handle 表示通过ScheduleHandle提交的单个任务，用于查询或等待它执行结束
+type Handle struct {
+	done chan struct{} // 任务执行结束后关闭，包括panic的情况
+}
+
+// ScheduleHandle 提交一个任务到工作池执行，返回用于查询该任务是否执行结束的Handle，阻塞和错误的语义与Schedule相同
+// Handle只持有一个通道，任务结束后没有其他引用时随之被回收
+func (p *Pool) ScheduleHandle(t Task) (*Handle, error) {
+	h := &Handle{done: make(chan struct{})}
+
+	err := p.Schedule(func() {
+		defer close(h.done)
+		t()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Done 返回一个在任务执行结束后关闭的通道，任务panic时同样关闭
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Completed 返回任务是否已执行结束
+func (h *Handle) Completed() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Outcome 表示通过ScheduleResult提交的任务的执行结果
+type Outcome struct {
+	Value any   // 任务返回值
+	Err   error // 任务返回的错误，任务panic时为包装了ErrTaskPanic的错误
+}
+
+// ScheduleResult 提交一个带返回值的任务到工作池执行，返回的通道在任务结束后传递一次执行结果
+// 通道缓冲区大小为1，调用方不读取也不会阻塞worker；提交失败时返回错误，通道为nil
+func (p *Pool) ScheduleResult(fn func() (any, error)) (<-chan Outcome, error) {
+	ch := make(chan Outcome, 1)
+
+	err := p.Schedule(func() {
+		var o Outcome
+		defer func() {
+			if r := recover(); r != nil {
+				o = Outcome{Err: fmt.Errorf("%w: %v", ErrTaskPanic, r)}
+			}
+			ch <- o
+		}()
+
+		o.Value, o.Err = fn()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// waiter 定义SubmitWait使用的可复用任务包装，run在创建时绑定一次，复用时不再分配闭包
+type waiter struct {
+	task Task          // 待执行的任务
+	done chan struct{} // 任务结束后写入一个信号，读取后可重复使用
+	run  Task          // 执行task并发送结束信号的包装任务
+}
+
+// waiterPool 复用SubmitWait的任务包装，减少高频调用时的内存分配
+var waiterPool = sync.Pool{
+	New: func() any {
+		w := &waiter{done: make(chan struct{}, 1)}
+		w.run = func() {
+			defer func() { w.done <- struct{}{} }()
+			w.task()
+		}
+		return w
+	},
+}
+
+// SubmitWait 提交一个任务到工作池执行，并阻塞等待该任务执行结束
+// 任务panic时同样会返回，panic仍交由工作池的panic处理流程处理；提交失败时返回提交错误
+// 任务被Free随队列丢弃时返回ErrWorkerPoolFreed；优雅关闭时队列中的任务仍会执行，照常等待其结束
+func (p *Pool) SubmitWait(t Task) error {
+	w := waiterPool.Get().(*waiter)
+	w.task = t
+
+	quit, freed := p.quit, p.freed
+	if err := p.Schedule(w.run); err != nil {
+		w.recycle()
+		return err
+	}
+
+	select {
+	case <-w.done:
+		w.recycle()
+		return nil
+	case <-quit:
+	}
+
+	// 与awaitTasks相同，释放完成后任务要么已执行结束，要么已被丢弃
+	select {
+	case <-w.done:
+		w.recycle()
+		return nil
+	case <-freed:
+	}
+
+	select {
+	case <-w.done:
+		w.recycle()
+		return nil
+	default:
+		// 丢弃的包装任务可能仍被队列引用，不能放回waiterPool，交由垃圾回收
+		return ErrWorkerPoolFreed
+	}
+}
+
+// recycle 清空任务引用后将w放回waiterPool，调用时done中的信号已被读取，保证复用时不会残留上一个任务的状态
+func (w *waiter) recycle() {
+	w.task = nil
+	waiterPool.Put(w)
+}
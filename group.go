@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TaskGroup 表示一组通过同一个工作池执行的相关任务，可以统一等待它们全部完成
+// 组内任务与其他任务共享工作池的worker，同样受容量、队列等限制
+type TaskGroup struct {
+	pool  *Pool
+	tasks taskCounter
+
+	errOnce sync.Once
+	err     error // 组内第一个panic的任务对应的错误
+}
+
+// Group 创建一个使用当前工作池执行任务的任务组
+func (p *Pool) Group() *TaskGroup {
+	return &TaskGroup{pool: p}
+}
+
+// Go 通过工作池提交一个属于该组的任务，提交失败时返回提交错误，该任务不计入Wait等待的范围
+func (g *TaskGroup) Go(t Task) error {
+	g.tasks.add()
+
+	err := g.pool.Schedule(func() {
+		defer g.tasks.done()
+		defer func() {
+			if r := recover(); r != nil {
+				g.errOnce.Do(func() { g.err = fmt.Errorf("%w: %v", ErrTaskPanic, r) })
+			}
+		}()
+
+		t()
+	})
+	if err != nil {
+		g.tasks.done()
+		return err
+	}
+
+	return nil
+}
+
+// Wait 阻塞等待组内所有已提交的任务执行完成，有任务panic时返回第一个包装了ErrTaskPanic的错误
+// 工作池被Free释放、组内排队的任务被丢弃时返回ErrWorkerPoolFreed，不会一直阻塞；优雅关闭时这些任务仍会执行，Wait照常等待
+func (g *TaskGroup) Wait() error {
+	if err := g.pool.awaitTasks(g.tasks.wait()); err != nil {
+		return err
+	}
+	return g.err
+}
+
+// taskCounter 记录已提交且尚未结束的任务数量，与sync.WaitGroup不同，等待方通过通道等待，可以同时等待工作池被释放
+type taskCounter struct {
+	mu   sync.Mutex
+	n    int
+	zero chan struct{} // 有等待方时存在，n归零时关闭
+}
+
+// add 计入一个任务
+func (c *taskCounter) add() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+// done 扣除一个结束的任务，归零时唤醒所有等待方
+func (c *taskCounter) done() {
+	c.mu.Lock()
+	c.n--
+	if c.n == 0 && c.zero != nil {
+		close(c.zero)
+		c.zero = nil
+	}
+	c.mu.Unlock()
+}
+
+// wait 返回在任务数量归零时关闭的通道，当前已经为0时返回已关闭的通道
+func (c *taskCounter) wait() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.n == 0 {
+		return closedChan
+	}
+	if c.zero == nil {
+		c.zero = make(chan struct{})
+	}
+	return c.zero
+}
+
+// closedChan 是一个已关闭的通道，用于表示无需等待
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// awaitTasks 等待done关闭，done表示调用方通过工作池提交的一批任务全部结束
+// 工作池开始释放后等待释放流程完成：此时已接收的任务要么已执行结束，要么随队列被丢弃，done仍未关闭时返回ErrWorkerPoolFreed
+// 优雅关闭时已接收的任务都会执行，因此照常返回nil
+func (p *Pool) awaitTasks(done <-chan struct{}) error {
+	// 未初始化的工作池拒绝全部提交，没有需要等待的任务
+	if !p.isInitialized() {
+		return nil
+	}
+	quit, freed := p.quit, p.freed
+
+	select {
+	case <-done:
+		return nil
+	case <-quit:
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-freed:
+	}
+
+	select {
+	case <-done:
+		return nil
+	default:
+		return ErrWorkerPoolFreed
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+// WithIdleCallback 设置工作池空闲时回调的选项，适合按需拉取任务的生产者在回调中获取并提交下一批任务
+// 所有任务执行完成、队列为空且没有阻塞在提交中的调用方时调用onIdle，连续的多次空闲只回调一次，直到再次有任务执行完成后空闲；被拒绝或失败的提交不会触发回调
+// 回调在专门的后台协程中执行，不占用worker；回调中可以提交任务，但不能同步调用Free等等待后台协程退出的方法
+func WithIdleCallback(onIdle func()) Option {
+	return func(p *Pool) {
+		p.onIdle = onIdle
+	}
+}
+
+// signalIdle 在任务执行完成后未完成任务计数归零时调用，非阻塞地通知空闲回调协程，调用方需持有pendingMu
+func (p *Pool) signalIdle() {
+	if p.idleSignal == nil {
+		return
+	}
+
+	select {
+	case p.idleSignal <- struct{}{}:
+	default:
+	}
+}
+
+// watchIdle 空闲回调协程主循环，收到通知后确认工作池仍然空闲再调用回调
+// 回调执行期间产生的多次通知合并为一次
+func (p *Pool) watchIdle() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-p.idleSignal:
+		}
+
+		p.pendingMu.Lock()
+		idle := p.pending == 0
+		p.pendingMu.Unlock()
+
+		if idle {
+			p.callHook(0, p.onIdle)
+		}
+	}
+}
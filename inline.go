@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 容量为0的工作池处于同步模式：不创建worker，Schedule等提交方法在调用方协程中直接执行任务并在任务结束后返回，
+// 任务panic时除了按工作池的方式处理外，还会以包装了ErrTaskPanic的错误返回给调用方，便于测试和确定性调试
+// 同步模式只由创建时的容量决定，之后通过Tune调整容量不会改变执行方式
+
+// runInline 在同步模式下于调用方协程中执行任务，任务panic时返回包装了ErrTaskPanic的错误
+func (p *Pool) runInline(t Task) (err error) {
+	if err := p.enterSchedule(); err != nil {
+		return err
+	}
+
+	// 计入wg后即可释放读锁，保证Free等待任务执行完成，同时任务中可以再次提交任务或释放工作池
+	p.wg.Add(1)
+	p.exitSchedule()
+	defer p.wg.Done()
+
+	p.submitted.Add(1)
+	p.execute(0, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v", ErrTaskPanic, r)
+				panic(r)
+			}
+		}()
+
+		t()
+	})
+
+	return err
+}
+
+// ScheduleReturn 提交任务，有空闲的worker名额时直接在调用方协程中执行，省去交给worker协程的开销，适合执行时间很短的任务
+// 在调用方协程中执行时ranInline为true，任务结束后才返回；没有空闲名额时与Schedule相同，将任务交给worker或放入队列
+// 空闲名额指存活worker数量低于容量，调用方在执行期间占用一个worker名额，同时执行的任务数量仍不超过容量
+// 任务队列或优先级队列中有等待的任务、工作池暂停、按提交顺序开始执行或手动分发时不在调用方执行，避免越过等待中的任务
+// 同步模式下总是在调用方执行；任务panic时与在worker中一样被捕获处理
+func (p *Pool) ScheduleReturn(t Task) (ranInline bool, err error) {
+	if !p.isInitialized() {
+		return false, ErrPoolNotInitialized
+	}
+
+	if p.synchronous {
+		err := p.runInline(t)
+		return err == nil || errors.Is(err, ErrTaskPanic), err
+	}
+
+	if p.inlineAllowed() {
+		if ran, err := p.tryInline(t); ran || err != nil {
+			return ran, err
+		}
+	}
+	return false, p.Schedule(t)
+}
+
+// inlineAllowed 返回当前是否可以在调用方协程中执行新任务
+func (p *Pool) inlineAllowed() bool {
+	if p.paused.Load() || p.orderedStart || p.manualDispatch {
+		return false
+	}
+	if p.QueueLen() > 0 || p.weightGate() != nil {
+		return false
+	}
+
+	p.prioMu.Lock()
+	defer p.prioMu.Unlock()
+	return p.prioLen() == 0
+}
+
+// tryInline 预留一个worker名额并在调用方协程中执行任务，没有空闲名额或有进行中的Barrier时返回false，由调用方改为正常提交
+func (p *Pool) tryInline(t Task) (bool, error) {
+	if err := p.enterSchedule(); err != nil {
+		return false, err
+	}
+	if p.barrier != nil || !p.reserveWorker() {
+		p.exitSchedule()
+		p.donePending()
+		return false, nil
+	}
+
+	// 与runInline相同，计入wg后释放读锁，保证Free等待任务执行完成
+	p.wg.Add(1)
+	p.exitSchedule()
+	defer p.wg.Done()
+	defer func() {
+		p.workers.Add(-1)
+		p.notify()
+	}()
+
+	p.submitted.Add(1)
+	p.execute(0, t)
+	return true, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+// ScheduleKeyed 提交一个带key的任务到工作池执行，key相同的任务按提交顺序逐个执行，不会并发
+// 不同key的任务之间仍然共享工作池的worker并行执行
+// 同一key已有任务在执行时，新任务进入该key的等待队列并立即返回，由执行前一个任务的worker在完成后接着执行
+// 等待队列中的任务同样遵守Pause，工作池被Free释放时被丢弃；key的首个任务被PolicyDropOldest丢弃时，等待队列中的下一个任务接替它重新交付
+func (p *Pool) ScheduleKeyed(key string, t Task) error {
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+
+	p.keyedMu.Lock()
+	if waiting, ok := p.keyed[key]; ok {
+		p.keyed[key] = append(waiting, t)
+		p.keyedMu.Unlock()
+
+		p.addPending()
+		p.submitted.Add(1)
+		return nil
+	}
+	if p.keyed == nil {
+		p.keyed = make(map[string][]Task)
+	}
+	p.keyed[key] = nil
+	p.keyedMu.Unlock()
+
+	w := p.keyedStarter(key, t)
+	err := p.Schedule(w)
+	if err != nil {
+		p.cancelDrop(w)
+
+		// 提交失败时丢弃在此期间进入等待队列的任务
+		p.keyedMu.Lock()
+		waiting := p.keyed[key]
+		delete(p.keyed, key)
+		p.keyedMu.Unlock()
+
+		for range waiting {
+			p.donePending()
+		}
+		if len(waiting) > 0 {
+			p.logf("%d keyed tasks dropped: %v\n", len(waiting), err)
+		}
+	}
+
+	return err
+}
+
+// keyedStarter 返回在worker上执行key的首个任务t并接着执行等待队列的包装任务，并登记它被丢弃时的处理
+func (p *Pool) keyedStarter(key string, t Task) Task {
+	var w Task
+	w = func() {
+		p.cancelDrop(w)
+		p.runKeyed(key, t)
+	}
+	p.onDrop(w, func() { p.keyedDropped(key) })
+	return w
+}
+
+// keyedDropped 在key的包装任务被丢弃后调用，它携带的任务随之被丢弃
+// 等待队列中的下一个任务通过溢出缓冲区接替交付，没有等待的任务时释放key，避免key被永久占用
+func (p *Pool) keyedDropped(key string) {
+	p.keyedMu.Lock()
+	waiting := p.keyed[key]
+	if len(waiting) == 0 {
+		delete(p.keyed, key)
+		p.keyedMu.Unlock()
+		return
+	}
+	next := waiting[0]
+	p.keyed[key] = waiting[1:]
+	p.keyedMu.Unlock()
+
+	p.forward(p.keyedStarter(key, next))
+}
+
+// runKeyed 在worker上执行key的第一个任务，然后依次执行该key等待队列中的任务，直到队列为空
+// 等待队列中的任务与普通任务一样调用钩子并计入统计；任务panic时交给panic处理函数，不影响该key后续任务的执行
+// 与worker主循环一样，暂停期间不开始下一个任务，工作池被非优雅地释放后丢弃剩余的任务
+func (p *Pool) runKeyed(key string, t Task) {
+	p.tryRun(t)
+
+	for {
+		p.waitUnpaused()
+
+		p.keyedMu.Lock()
+		waiting := p.keyed[key]
+		if len(waiting) == 0 || p.discarding() {
+			delete(p.keyed, key)
+			p.keyedMu.Unlock()
+
+			for range waiting {
+				p.donePending()
+			}
+			if len(waiting) > 0 {
+				p.logf("%d keyed tasks dropped: %v\n", len(waiting), ErrWorkerPoolFreed)
+			}
+			return
+		}
+		t = waiting[0]
+		p.keyed[key] = waiting[1:]
+		p.keyedMu.Unlock()
+
+		p.runChained(t)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// WithSlowTaskThreshold 设置慢任务阈值的选项，默认不检测
+// 通过ScheduleLabeled提交的任务执行时间超过d时，通过日志输出带有任务标签的警告
+func WithSlowTaskThreshold(d time.Duration) Option {
+	return func(p *Pool) {
+		p.slowThreshold = d
+	}
+}
+
+// ScheduleLabeled 提交一个带标签的任务到工作池执行，阻塞和错误的语义与Schedule相同
+// 标签用于在日志中识别任务，配置了WithSlowTaskThreshold时任务执行时间超过阈值会输出警告
+// 执行中的带标签任务会被记录，GracefulStop超时时返回的ShutdownError中列出它们的标签
+func (p *Pool) ScheduleLabeled(label string, t Task) error {
+	return p.Schedule(func() {
+		p.enterLabel(label)
+		defer p.exitLabel(label)
+
+		if p.slowThreshold > 0 {
+			start := p.clock.Now()
+			defer p.checkSlow(label, start)
+		}
+
+		t()
+	})
+}
+
+// checkSlow 在带标签的任务执行结束后检查执行时间是否超过慢任务阈值，任务panic时同样检查
+func (p *Pool) checkSlow(label string, start time.Time) {
+	if d := p.clock.Now().Sub(start); d > p.slowThreshold {
+		p.logf("slow task[%s] took %v\n", label, d)
+	}
+}
+
+// enterLabel 在带标签的任务开始执行时记录其标签，标签可以重复
+func (p *Pool) enterLabel(label string) {
+	p.labelsMu.Lock()
+	if p.labels == nil {
+		p.labels = make(map[string]int)
+	}
+	p.labels[label]++
+	p.labelsMu.Unlock()
+}
+
+// exitLabel 在带标签的任务执行结束后移除其标签，任务panic时同样移除
+func (p *Pool) exitLabel(label string) {
+	p.labelsMu.Lock()
+	if p.labels[label]--; p.labels[label] == 0 {
+		delete(p.labels, label)
+	}
+	p.labelsMu.Unlock()
+}
+
+// runningLabels 返回执行中的带标签任务的标签，按字典序排列，同一标签的多个任务只出现一次
+func (p *Pool) runningLabels() []string {
+	p.labelsMu.Lock()
+	defer p.labelsMu.Unlock()
+
+	if len(p.labels) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(p.labels))
+	for label := range p.labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencySubBuckets 每个2的幂区间划分的子区间数量，统计误差不超过区间宽度的一半，约为6%
+const latencySubBuckets = 8
+
+// latencyBuckets 直方图的区间数量，覆盖int64范围内的所有纳秒数
+const latencyBuckets = 64 * latencySubBuckets
+
+// latencyHistogram 记录任务执行时间的对数线性直方图，每次记录只有几次原子加法
+type latencyHistogram struct {
+	count   atomic.Uint64
+	sum     atomic.Uint64 // 所有执行时间之和，单位为纳秒
+	buckets [latencyBuckets]atomic.Uint64
+}
+
+// latencyBucket 返回执行时间d所在的区间编号
+func latencyBucket(d uint64) int {
+	if d < latencySubBuckets {
+		return int(d)
+	}
+	e := bits.Len64(d) - 1 // d位于[2^e, 2^(e+1))，e不小于3
+	sub := (d >> (e - 3)) & (latencySubBuckets - 1)
+	return (e-2)*latencySubBuckets + int(sub)
+}
+
+// latencyBucketMid 返回区间编号i对应区间的中点
+func latencyBucketMid(i int) uint64 {
+	if i < latencySubBuckets {
+		return uint64(i)
+	}
+	e := i/latencySubBuckets + 2
+	sub := uint64(i % latencySubBuckets)
+	width := uint64(1) << (e - 3)
+	return (latencySubBuckets+sub)*width + width/2
+}
+
+// record 记录一次任务执行时间
+func (h *latencyHistogram) record(d time.Duration) {
+	n := uint64(max(d, 0))
+	h.buckets[latencyBucket(n)].Add(1)
+	h.sum.Add(n)
+	h.count.Add(1)
+}
+
+// quantile 返回分位数q对应的执行时间，counts为各区间的计数快照，total为快照的总数
+func quantile(counts *[latencyBuckets]uint64, total uint64, q float64) time.Duration {
+	rank := uint64(q*float64(total-1)) + 1
+	var seen uint64
+	for i, c := range counts {
+		seen += c
+		if seen >= rank {
+			return time.Duration(latencyBucketMid(i))
+		}
+	}
+	return 0
+}
+
+// LatencyStats 返回任务执行时间的平均值、中位数和99分位数，没有任务执行完成时均为0
+// 执行时间从任务开始执行到返回（包括panic），不包括排队时间；分位数为近似值，误差约为6%
+// 统计与任务并发进行时各项数值来自不同时刻，只是近似的快照
+func (p *Pool) LatencyStats() (avg, p50, p99 time.Duration) {
+	if !p.isInitialized() {
+		return 0, 0, 0
+	}
+
+	h := &p.latency
+
+	var counts [latencyBuckets]uint64
+	var total uint64
+	for i := range h.buckets {
+		counts[i] = h.buckets[i].Load()
+		total += counts[i]
+	}
+	if total == 0 {
+		return 0, 0, 0
+	}
+
+	if n := h.count.Load(); n > 0 {
+		avg = time.Duration(h.sum.Load() / n)
+	}
+	return avg, quantile(&counts, total, 0.5), quantile(&counts, total, 0.99)
+}
@@ -0,0 +1,118 @@
+package main
+
+import "sync"
+
+// Limiter 表示共享同一个工作池、但限制自身并发数量的一组任务，例如在容量为50的工作池中最多同时发送3封邮件
+// 超过上限的任务在Limiter内部排队，不会占用worker，由执行完前一个任务的worker接着执行
+type Limiter struct {
+	pool *Pool
+	max  int
+
+	mu      sync.Mutex
+	running int    // 已交给工作池且尚未结束的任务数量
+	waiting []Task // 因达到上限而在Limiter内部排队的任务
+}
+
+// Limiter 创建一个通过当前工作池执行任务、最多同时执行n个任务的Limiter，n不大于0时按1处理
+func (p *Pool) Limiter(n int) *Limiter {
+	return &Limiter{pool: p, max: max(n, 1)}
+}
+
+// Schedule 提交一个受Limiter并发上限约束的任务，阻塞和错误的语义与工作池的Schedule相同
+// 已达到上限时任务进入Limiter内部的等待队列并立即返回，计入工作池的WaitIdle等待范围
+// 等待队列中的任务同样遵守Pause，工作池被Free释放时被丢弃；交给工作池的任务被PolicyDropOldest丢弃时，等待队列中的下一个任务接替它重新交付
+func (l *Limiter) Schedule(t Task) error {
+	p := l.pool
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	if l.running >= l.max {
+		l.waiting = append(l.waiting, t)
+		l.mu.Unlock()
+
+		p.addPending()
+		p.submitted.Add(1)
+		return nil
+	}
+	l.running++
+	l.mu.Unlock()
+
+	w := l.starter(t)
+	err := p.Schedule(w)
+	if err != nil {
+		p.cancelDrop(w)
+		l.mu.Lock()
+		l.running--
+		l.mu.Unlock()
+	}
+	return err
+}
+
+// starter 返回在worker上执行t并接着执行等待队列的包装任务，并登记它被丢弃时的处理
+func (l *Limiter) starter(t Task) Task {
+	p := l.pool
+
+	var w Task
+	w = func() {
+		p.cancelDrop(w)
+		l.run(t)
+	}
+	p.onDrop(w, l.dropped)
+	return w
+}
+
+// dropped 在包装任务被丢弃后调用，它携带的任务随之被丢弃
+// 等待队列中的下一个任务通过溢出缓冲区接替交付，没有等待的任务时归还并发名额，避免Limiter的上限被永久占用
+func (l *Limiter) dropped() {
+	l.mu.Lock()
+	if len(l.waiting) == 0 {
+		l.running--
+		l.mu.Unlock()
+		return
+	}
+	next := l.waiting[0]
+	l.waiting[0] = nil
+	l.waiting = l.waiting[1:]
+	l.mu.Unlock()
+
+	l.pool.forward(l.starter(next))
+}
+
+// run 在worker上执行t，然后依次执行等待队列中的任务，直到等待队列为空
+// 等待队列中的任务与普通任务一样调用钩子并计入统计；任务panic时交给panic处理函数，不影响后续任务的执行
+// 与worker主循环一样，暂停期间不开始下一个任务，工作池被非优雅地释放后丢弃剩余的任务
+func (l *Limiter) run(t Task) {
+	p := l.pool
+	p.tryRun(t)
+
+	for {
+		p.waitUnpaused()
+
+		l.mu.Lock()
+		if len(l.waiting) == 0 {
+			l.running--
+			l.mu.Unlock()
+			return
+		}
+		if p.discarding() {
+			waiting := l.waiting
+			l.waiting = nil
+			l.running--
+			l.mu.Unlock()
+
+			for range waiting {
+				p.donePending()
+			}
+			p.logf("%d limited tasks dropped: %v\n", len(waiting), ErrWorkerPoolFreed)
+			return
+		}
+		t = l.waiting[0]
+		l.waiting[0] = nil
+		l.waiting = l.waiting[1:]
+		l.mu.Unlock()
+
+		p.runChained(t)
+	}
+}
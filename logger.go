@@ -0,0 +1,56 @@
+package main
+
+// Logger 定义工作池使用的日志接口
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// noopLogger 默认的日志实现，丢弃所有日志
+type noopLogger struct{}
+
+// Printf 实现Logger接口，不输出任何内容
+func (noopLogger) Printf(string, ...any) {}
+
+// WithLogger 设置工作池日志输出的选项，默认不输出任何日志
+func WithLogger(l Logger) Option {
+	return func(p *Pool) {
+		if l != nil {
+			p.logger = l
+		}
+	}
+}
+
+// WithDebug 设置是否输出调试日志的选项，开启后每个任务被worker接收时都会输出一行日志
+// 调试日志位于任务执行的热路径上，默认关闭
+func WithDebug(b bool) Option {
+	return func(p *Pool) {
+		p.debug = b
+	}
+}
+
+// WithName 设置工作池名称的选项，名称会出现在每一行日志中
+func WithName(name string) Option {
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+// Name 返回工作池名称
+func (p *Pool) Name() string {
+	if !p.isInitialized() {
+		return ""
+	}
+	return p.name
+}
+
+// logf 输出带有工作池名称前缀的日志
+func (p *Pool) logf(format string, args ...any) {
+	p.logger.Printf("pool[%s] "+format, append([]any{p.name}, args...)...)
+}
+
+// debugf 在开启调试日志时输出日志
+func (p *Pool) debugf(format string, args ...any) {
+	if p.debug {
+		p.logf(format, args...)
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
-import "time"
+import (
+	"log"
+	"time"
+)
 
 func main() {
-	p := New(500, WithPreAlloc(true))
+	p := New(500, WithPreAlloc(true), WithLogger(log.Default()))
 
 	for i := 0; i < 10; i++ {
 		err := p.Schedule(func() {
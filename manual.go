@@ -0,0 +1,43 @@
+package main
+
+// WithManualDispatch 设置是否开启手动分发模式的选项，默认关闭
+// 开启后工作池不创建worker，也不启动run协程，提交的任务只在调用方调用Dispatch时在调用方协程中逐个执行
+// 适用于将工作池嵌入其他调度器，或在模拟和测试中完全控制任务推进的时机；无缓冲的任务队列下Schedule会阻塞到Dispatch取走任务
+// 预创建、创建策略、空闲超时等与worker相关的选项在该模式下不生效
+func WithManualDispatch(b bool) Option {
+	return func(p *Pool) {
+		p.manualDispatch = b
+	}
+}
+
+// Dispatch 在调用方协程中执行一个等待中的任务，优先执行优先级最高的优先级任务，没有等待的任务时返回false
+// 任务panic的处理方式与worker中相同；通常与WithManualDispatch配合使用，非手动分发模式下调用会与worker争抢任务
+func (p *Pool) Dispatch() bool {
+	if !p.isInitialized() {
+		return false
+	}
+	if item, ok := p.popPriority(); ok {
+		t := item.task
+		item.release()
+		p.execute(0, t)
+		return true
+	}
+	if t := p.popQueue(); t != nil {
+		p.execute(0, t)
+		return true
+	}
+
+	for {
+		select {
+		case t := <-p.taskChan():
+			// 任务队列被ResizeQueue替换后，旧的通道关闭时接收到nil，重新读取新的通道
+			if t == nil {
+				continue
+			}
+			p.execute(0, t)
+			return true
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Map 通过工作池对items中的每个元素并发执行fn，返回结果的顺序与items一致
+// 任意一个元素执行失败时返回最先出现的错误，尚未开始执行的元素会被跳过；fn的panic会作为包装了ErrTaskPanic的错误返回
+// 每个元素作为一个任务提交，并发度受工作池容量限制；工作池被Free释放、尚未执行的元素被丢弃时返回ErrWorkerPoolFreed
+func Map[T, R any](p *Pool, items []T, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+
+	var (
+		tasks    taskCounter
+		once     sync.Once
+		firstErr error
+		failed   atomic.Bool
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			failed.Store(true)
+		})
+	}
+
+	for i, item := range items {
+		if failed.Load() {
+			break
+		}
+
+		tasks.add()
+		err := p.Schedule(func() {
+			defer tasks.done()
+			defer func() {
+				if r := recover(); r != nil {
+					fail(fmt.Errorf("%w: %v", ErrTaskPanic, r))
+				}
+			}()
+
+			// 已有元素失败时跳过剩余元素
+			if failed.Load() {
+				return
+			}
+
+			r, err := fn(item)
+			if err != nil {
+				fail(err)
+				return
+			}
+			results[i] = r
+		})
+		if err != nil {
+			tasks.done()
+			fail(err)
+			break
+		}
+	}
+
+	if err := p.awaitTasks(tasks.wait()); err != nil {
+		return nil, err
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
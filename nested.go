@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// ScheduleNested 供正在执行的任务向同一个工作池提交子任务
+// 从该工作池的worker中调用且没有worker可以立即接收子任务时返回ErrWouldDeadlock，
+// 避免所有worker都阻塞在提交子任务上而无人执行任务；调用方可以选择在当前worker中直接执行子任务
+// 不是从该工作池的worker中调用时与Schedule相同
+func (p *Pool) ScheduleNested(t Task) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if !p.inWorker() {
+		return p.Schedule(t)
+	}
+
+	err := p.TrySchedule(t)
+	if err == ErrPoolBusy {
+		return ErrWouldDeadlock
+	}
+	return err
+}
+
+// markWorker 将当前协程标记为该工作池的worker，并记录worker的状态，返回用于取消标记的函数
+func (p *Pool) markWorker(ws *workerState) func() {
+	id := goid()
+	p.workerGoroutines.Store(id, ws)
+	return func() { p.workerGoroutines.Delete(id) }
+}
+
+// inWorker 返回当前协程是否为该工作池的worker
+func (p *Pool) inWorker() bool {
+	_, ok := p.workerGoroutines.Load(goid())
+	return ok
+}
+
+// currentWorker 返回当前协程所在worker的状态，当前协程不是该工作池的worker时返回nil
+func (p *Pool) currentWorker() *workerState {
+	ws, _ := p.workerGoroutines.Load(goid())
+	if ws == nil {
+		return nil
+	}
+	return ws.(*workerState)
+}
+
+// goid 从调用栈信息中解析当前协程的编号，Go没有提供协程本地存储，只能以此区分调用方所在的协程
+func goid() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	// 调用栈的第一行形如"goroutine 123 [running]:"
+	b := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,39 @@
+package main
+
+// ScheduleOnce 提交一个带key的任务到工作池执行，同一key的任务在排队或执行期间不会被重复提交
+// key相同的任务已在排队或执行时直接返回scheduled=false，不会提交第二份；任务执行结束（包括panic）后该key可以再次提交
+// 提交失败时返回错误，scheduled为false
+func (p *Pool) ScheduleOnce(key string, t Task) (scheduled bool, err error) {
+	if !p.isInitialized() {
+		return false, ErrPoolNotInitialized
+	}
+
+	p.onceMu.Lock()
+	if _, ok := p.onceKeys[key]; ok {
+		p.onceMu.Unlock()
+		return false, nil
+	}
+	if p.onceKeys == nil {
+		p.onceKeys = make(map[string]struct{})
+	}
+	p.onceKeys[key] = struct{}{}
+	p.onceMu.Unlock()
+
+	err = p.Schedule(func() {
+		defer p.doneOnce(key)
+		t()
+	})
+	if err != nil {
+		p.doneOnce(key)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// doneOnce 移除key，使其可以再次通过ScheduleOnce提交
+func (p *Pool) doneOnce(key string) {
+	p.onceMu.Lock()
+	delete(p.onceKeys, key)
+	p.onceMu.Unlock()
+}
@@ -0,0 +1,14 @@
+package main
+
+// inTurn 包装持有执行权的worker取到的任务，任务开始执行时交出执行权，让下一个worker按顺序取任务
+func (p *Pool) inTurn(t Task) Task {
+	return func() {
+		p.releaseTurn()
+		t()
+	}
+}
+
+// releaseTurn 交出按提交顺序开始执行的执行权
+func (p *Pool) releaseTurn() {
+	<-p.startTurn
+}
@@ -0,0 +1,68 @@
+package main
+
+// Pause 暂停执行新任务，与Drain不同，暂停期间仍然接收新任务，任务在队列中等待（受队列容量限制）
+// 暂停期间worker在执行下一个任务前阻塞，已经开始执行的任务不受影响；调用Unpause后worker继续执行等待中的任务
+// 优先级任务同样被暂停；工作池被释放时worker不再等待
+func (p *Pool) Pause() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+
+	if p.resumed != nil {
+		return
+	}
+	p.resumed = make(chan struct{})
+	p.paused.Store(true)
+	p.logf("workerpool paused\n")
+}
+
+// Unpause 恢复执行任务，与Pause配合使用，工作池未暂停时调用没有效果
+func (p *Pool) Unpause() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+
+	if p.resumed == nil {
+		return
+	}
+	p.paused.Store(false)
+	close(p.resumed)
+	p.resumed = nil
+	p.logf("workerpool unpaused\n")
+}
+
+// Paused 返回工作池当前是否处于暂停状态
+func (p *Pool) Paused() bool {
+	if !p.isInitialized() {
+		return false
+	}
+	return p.paused.Load()
+}
+
+// pauseGate 工作池暂停时返回一个在Unpause后关闭的通道，未暂停时返回nil
+func (p *Pool) pauseGate() <-chan struct{} {
+	if !p.paused.Load() {
+		return nil
+	}
+
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+
+	return p.resumed
+}
+
+// waitUnpaused 等待工作池恢复执行，用于worker创建时携带的第一个任务，工作池被释放时不再等待
+func (p *Pool) waitUnpaused() {
+	if gate := p.pauseGate(); gate != nil {
+		select {
+		case <-gate:
+		case <-p.quit:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+// QueueFullPolicy 定义任务队列已满且没有空闲worker时Schedule的处理策略
+type QueueFullPolicy int
+
+const (
+	PolicyBlock      QueueFullPolicy = iota // 阻塞等待直到任务被接收，默认策略
+	PolicyReject                            // 立即返回ErrPoolBusy，与WithNonBlocking(true)一致
+	PolicyDropOldest                        // 丢弃任务队列中最早的任务，为新任务腾出位置
+)
+
+// String 返回策略的可读名称
+func (q QueueFullPolicy) String() string {
+	switch q {
+	case PolicyBlock:
+		return "block"
+	case PolicyReject:
+		return "reject"
+	case PolicyDropOldest:
+		return "drop-oldest"
+	default:
+		return "unknown"
+	}
+}
+
+// WithQueueFullPolicy 设置任务队列已满时处理策略的选项，默认值为：PolicyBlock
+// PolicyDropOldest只作用于带缓冲的任务队列，未设置WithQueueSize时退化为PolicyBlock，NewWithError则返回ErrInvalidOption
+// 被丢弃的任务不会执行，也不再计入WaitIdle等待的任务；依赖任务执行来收尾的提交方式不会得到通知：
+// 通过ScheduleOnce提交的任务被丢弃后其key一直被占用，直到Reset；Submit返回的Future、ScheduleHandle返回的Handle以及ScheduleNotify的通知channel不会完成
+func WithQueueFullPolicy(policy QueueFullPolicy) Option {
+	return func(p *Pool) {
+		p.queueFullPolicy = policy
+	}
+}
+
+// scheduleDropOldest 不断丢弃任务队列中最早的任务，直到t被空闲worker或任务队列接收，queued的含义与schedule相同
+func (p *Pool) scheduleDropOldest(t Task) (queued bool, err error) {
+	tasks := p.taskChan()
+	for {
+		select {
+		case tasks <- t:
+			return true, nil
+		case p.spawn <- t:
+			return false, nil
+		default:
+		}
+
+		select {
+		case <-p.quit:
+			return false, ErrWorkerPoolFreed
+		case old := <-tasks:
+			p.donePending()
+			p.rejected.Add(1)
+			p.dropped(old)
+			p.debugf("workerpool drop oldest task\n")
+		default:
+		}
+	}
+}
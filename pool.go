@@ -1,14 +1,61 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	// ErrWorkerPoolFreed 表示工作池已被释放的错误
 	ErrWorkerPoolFreed = errors.New("workerpool freed")
+	// ErrInvalidCapacity 表示工作池容量非法（为负数或超过最大容量）的错误
+	ErrInvalidCapacity = errors.New("invalid workerpool capacity")
+	// ErrPoolBusy 表示工作池当前没有可以立即接收任务的worker的错误
+	ErrPoolBusy = errors.New("workerpool busy")
+	// ErrTaskPanic 表示任务执行过程中发生panic的错误
+	ErrTaskPanic = errors.New("task panic")
+	// ErrPoolOverloaded 表示阻塞等待提交任务的调用方数量已达上限的错误
+	ErrPoolOverloaded = errors.New("workerpool overloaded")
+	// ErrScheduleTimeout 表示在指定时间内没有worker接收任务的错误
+	ErrScheduleTimeout = errors.New("workerpool schedule timeout")
+	// ErrShutdownTimeout 表示在指定时间内worker没有全部退出的错误
+	ErrShutdownTimeout = errors.New("workerpool shutdown timeout")
+	// ErrInvariantViolated 表示工作池内部状态不一致的错误
+	ErrInvariantViolated = errors.New("workerpool invariant violated")
+	// ErrPoolNotFreed 表示工作池尚未被释放，不能执行Reset的错误
+	ErrPoolNotFreed = errors.New("workerpool not freed")
+	// ErrPoolDraining 表示工作池正在排空，暂停接收新任务的错误
+	ErrPoolDraining = errors.New("workerpool draining")
+	// ErrInvalidOption 表示选项取值非法或选项之间互相冲突的错误
+	ErrInvalidOption = errors.New("invalid workerpool option")
+	// ErrWouldDeadlock 表示在worker中提交子任务会因没有可用的worker而死锁的错误
+	ErrWouldDeadlock = errors.New("workerpool schedule would deadlock")
+	// ErrTaskTimeout 表示任务没有在指定时间内执行完成的错误
+	ErrTaskTimeout = errors.New("workerpool task timeout")
+	// ErrInvalidWeight 表示加权任务的权重非法（小于1或超过容量）的错误
+	ErrInvalidWeight = errors.New("invalid task weight")
+	// ErrPoolNotInitialized 表示工作池没有通过New等构造函数创建（例如零值或nil）的错误
+	ErrPoolNotInitialized = errors.New("workerpool not initialized")
+	// ErrQueueTooSmall 表示调整后的任务队列容纳不下已排队任务的错误
+	ErrQueueTooSmall = errors.New("workerpool queue too small")
+	// ErrStartupProbe 表示启动探测时工作池未能在限定时间内执行探测任务的错误
+	ErrStartupProbe = errors.New("workerpool startup probe failed")
+	// ErrResultTimeout 表示在指定时间内任务没有完成，放弃等待其结果的错误
+	ErrResultTimeout = errors.New("workerpool result timeout")
+	// ErrPoolResetting 表示工作池正在通过Reset重新初始化，暂时不接收任务的错误
+	ErrPoolResetting = errors.New("workerpool resetting")
+	// ErrTaskTooLarge 表示任务大小超过WithMaxInFlightBytes设置的上限，永远无法执行的错误
+	ErrTaskTooLarge = errors.New("task too large")
+	// ErrUnorderedQueue 表示自定义任务队列不保证先进先出，无法确定调用前已提交的任务是否均已被取走的错误
+	ErrUnorderedQueue = errors.New("workerpool queue unordered")
 )
 
 // Task 定义任务类型，是一个无参数无返回值的函数
@@ -19,14 +66,146 @@ type Option func(pool *Pool)
 
 // Pool 定义工作池结构体
 type Pool struct {
-	preAlloc bool // 是否在创建pool的时候就预创建workers，默认值为：false
-	capacity int  // workerpool大小
+	initialized bool // 是否通过New等构造函数创建，零值Pool为false
 
-	active chan struct{} // 控制并发worker数量的信号通道
-	tasks  chan Task     // 任务队列通道
+	preAlloc    bool          // 是否在创建pool的时候就预创建workers，默认值为：false
+	preSpawn    int           // 创建pool时预创建的worker数量，不超过容量，默认值为：0
+	rampUp      time.Duration // 预创建的worker在该时间内均匀错开创建，默认值为：0，即同时创建
+	minWorkers  int           // 空闲超时后仍然保留的最小worker数量，默认值为：0
+	capacity    atomic.Int64  // workerpool大小，可通过Tune动态调整
+	queueSize   int           // 任务队列缓冲区大小，默认值为：0，即无缓冲
+	synchronous bool          // 是否为同步模式，创建时容量为0的工作池在调用方协程中直接执行任务
 
-	wg   sync.WaitGroup // 用于在pool销毁时等待所有worker退出
-	quit chan struct{}  // 用于通知各个worker退出的信号channel
+	maxBlocking     int             // 允许同时阻塞在Schedule中的调用方数量上限，默认值为：0，即不限制
+	nonBlocking     bool            // 是否为非阻塞模式，开启后Schedule在无法立即接收任务时返回ErrPoolBusy
+	queueFullPolicy QueueFullPolicy // 任务队列已满时的处理策略，默认值为：PolicyBlock
+	overflow        *Pool           // 无法立即接收任务时转交任务的溢出工作池，默认为nil
+	blocking        atomic.Int64    // 当前阻塞在Schedule中的调用方数量
+
+	workers          atomic.Int64              // 当前存活的worker数量，不超过capacity
+	ids              workerIDs                 // 可复用的worker编号，编号不超过曾经设置过的最大容量
+	statesMu         sync.Mutex                // 保护states
+	states           map[int]*workerState      // 存活worker的编号及其状态
+	workerGoroutines sync.Map                  // 存活worker所在协程的编号，用于判断ScheduleNested的调用方是否为worker
+	wakeup           chan struct{}             // 通知run重新检查是否需要创建worker的信号通道
+	spawn            chan Task                 // 没有空闲worker时将任务交给run，由run创建新worker执行
+	startTurn        chan struct{}             // 按提交顺序开始执行时的执行权，容量为1，持有者才能从任务队列取任务
+	retire           chan struct{}             // 通知空闲worker检查是否需要因缩容而退出的信号通道
+	tasks            atomic.Pointer[chan Task] // 任务队列通道，可通过ResizeQueue替换，通过taskChan读取
+	urgent           chan Task                 // 分发协程向worker发送优先级任务的通道，worker优先从该通道获取任务
+
+	prioMu     sync.Mutex    // 保护prioQueue、fair和prioSeq
+	prioQueue  priorityQueue // 优先级任务队列
+	fair       *fairQueue    // 配置了WithFairness时代替prioQueue按权重轮转分发
+	prioSeq    uint64        // 优先级任务提交序号
+	prioSignal chan struct{} // 通知分发协程有新的优先级任务
+
+	timersMu sync.Mutex         // 保护timers
+	timers   map[Timer]struct{} // 尚未到期的延迟任务定时器
+
+	keyedMu sync.Mutex        // 保护keyed
+	keyed   map[string][]Task // 有任务正在执行的key及其等待执行的任务
+
+	dropHooks sync.Map // 以任务闭包地址为key的丢弃回调，只在PolicyDropOldest下登记
+
+	barrierMu sync.Mutex // 保证同一时刻只有一个Barrier在进行
+	barrier   *barrier   // 进行中的Barrier，不为nil时新提交的任务需要等待屏障打开后才开始执行，由acceptMu保护
+
+	replaceMu sync.Mutex               // 保护replacing
+	replacing map[string]*replaceEntry // 通过ScheduleReplace提交且尚未开始执行的任务
+
+	affinityMu sync.Mutex              // 保护affinity
+	affinity   map[string]*workerState // 亲和性key及最近执行过该key任务的worker
+
+	labelsMu sync.Mutex     // 保护labels
+	labels   map[string]int // 执行中的带标签任务的标签及其数量
+
+	maxBytes      int64         // 通过ScheduleSized提交的任务总大小上限，不大于0时不限制
+	bytesMu       sync.Mutex    // 保护bytesFreed，修改inFlightBytes时同样需要持有
+	inFlightBytes atomic.Int64  // 通过ScheduleSized提交、已开始且尚未执行结束的任务的总大小
+	bytesFreed    chan struct{} // 有任务等待时存在，任务结束扣除大小后关闭以唤醒它们
+
+	spillMu  sync.Mutex // 保护spill和spilling
+	spill    []Task     // SubmitAndForget的溢出缓冲区
+	spilling bool       // 是否有后台协程正在交付溢出缓冲区中的任务
+
+	onceMu   sync.Mutex          // 保护onceKeys
+	onceKeys map[string]struct{} // 通过ScheduleOnce提交且正在排队或执行的key
+
+	signals []os.Signal    // 触发优雅关闭的信号
+	sigCh   chan os.Signal // 接收信号的通道
+
+	wg       sync.WaitGroup // 用于在pool销毁时等待所有worker退出
+	quit     chan struct{}  // 用于通知各个worker退出的信号channel
+	freed    chan struct{}  // 释放流程全部完成、worker均已退出后关闭，Reset时重新创建
+	freeOnce sync.Once      // 保证quit只被关闭一次，使Free可以重复调用
+	state    atomic.Int32   // 工作池运行状态，取值为State
+	graceful atomic.Bool    // 是否为优雅关闭，优雅关闭时worker在退出前会执行完队列中的任务
+	draining atomic.Bool    // 是否正在排空，为true时暂停接收新任务，由Drain和Resume切换
+	frozen   atomic.Bool    // 是否冻结了worker的创建，由FreezeWorkers和ThawWorkers切换
+	acceptMu sync.RWMutex   // 提交方交付任务期间持有读锁，释放和替换任务队列时获取写锁等待交付中的提交方退出
+	yieldMu  sync.Mutex     // 保护yield
+	yield    *acceptYield   // 当前一轮让出读锁的通知，没有提交方在阻塞交付、也没有调用方等待写锁时为nil
+
+	pauseMu sync.Mutex    // 保护resumed
+	paused  atomic.Bool   // 是否已暂停执行任务，由Pause和Unpause切换
+	resumed chan struct{} // 暂停期间存在，Unpause时关闭以唤醒等待的worker
+
+	running   atomic.Int64     // 正在执行的任务数量，由execute在任务开始执行和结束时增减
+	submitted atomic.Uint64    // 累计成功提交的任务数量
+	completed atomic.Uint64    // 累计执行完成的任务数量，包括panic的任务
+	panics    atomic.Uint64    // 累计发生panic的任务数量
+	rejected  atomic.Uint64    // 累计因容量不足被拒绝或丢弃的任务数量
+	highWater atomic.Int64     // 任务队列中排队任务数量曾经达到的最大值
+	latency   latencyHistogram // 任务执行时间的直方图
+
+	pendingMu sync.Mutex    // 保护pending、idle和idleArmed
+	pending   int           // 已提交但尚未执行完成的任务数量，包括排队中和执行中的任务
+	idle      chan struct{} // 有WaitIdle调用方等待时才创建，pending归零时关闭并置为nil
+	idleArmed bool          // 上次空闲之后有任务执行完成，pending归零时据此通知空闲回调，被拒绝的提交不会置位
+
+	availMu       sync.Mutex    // 保护avail
+	avail         chan struct{} // 有WaitAvailable调用方等待时才创建，任务执行结束或扩容时关闭并置为nil
+	availWaiters  atomic.Int64  // 等待空闲容量的WaitAvailable调用方数量，为0时任务执行结束无需加锁
+	saturated     atomic.Bool   // 是否处于饱和状态，用于保证两个饱和回调交替触发
+	extraWeight   atomic.Int64  // 执行中的加权任务在所在worker之外额外占用的并发单位数量
+	weightWaiting int64         // 已被worker取出、正在等待单位的加权任务数量，由availMu保护
+
+	runningMu      sync.Mutex    // 保护runningCh
+	runningCh      chan struct{} // 有WaitRunning调用方等待时才创建，任务开始执行时关闭并置为nil
+	runningWaiters atomic.Int64  // 等待的WaitRunning调用方数量，为0时任务开始执行无需加锁
+
+	name            string            // 工作池名称，用于区分同一进程中的多个工作池，默认自动生成
+	debug           bool              // 是否输出每个任务的调试日志，默认关闭
+	logger          Logger            // 日志输出，默认不输出
+	events          chan<- Event      // 接收生命周期事件的通道，为nil时不发送事件
+	panicHandler    func(any, []byte) // 任务panic时的处理函数，为nil时仅输出日志
+	repanic         func(any) bool    // 返回true时worker重新抛出任务的panic，为nil时全部恢复
+	noRecover       bool              // 是否不捕获任务panic，由WithRecover(false)开启
+	onStart         func()            // 任务开始执行前调用的钩子
+	onComplete      func(any)         // 任务执行结束后调用的钩子，参数为任务panic的值，未panic时为nil
+	onShutdown      func()            // 工作池释放时在所有worker退出后调用的清理函数
+	onSaturated     func()            // 正在执行任务的数量达到容量时调用的回调
+	onRelieved      func()            // 饱和后正在执行任务的数量降到容量以下时调用的回调
+	onDeadlock      func()            // 死锁检测发现工作池停滞时调用的回调
+	onIdle          func()            // 工作池空闲时调用的回调
+	idleSignal      chan struct{}     // 通知空闲回调协程未完成任务计数归零
+	workerInit      func(int) func()  // worker启动时调用的初始化函数，返回的清理函数在worker退出时调用
+	lockOSThread    bool              // worker是否在启动时独占操作系统线程
+	taskTimeout     time.Duration     // 通过ScheduleCtx提交的任务的执行超时时间，默认不超时
+	idleTimeout     time.Duration     // worker空闲超过该时间后退出，默认不退出
+	maxTasks        uint64            // worker执行完成该数量的任务后退出，为0时不限制
+	orderedStart    bool              // 是否保证任务按提交顺序开始执行
+	spawnStrategy   SpawnStrategy     // 创建worker的策略，默认值为：SpawnLazy
+	manualDispatch  bool              // 是否为手动分发模式，开启后任务只在调用Dispatch时执行
+	startupProbe    bool              // NewWithError返回前是否探测工作池能否执行任务
+	queue           TaskQueue         // 通过WithQueue设置的自定义任务队列
+	custom          *customQueue      // 包装自定义任务队列，未设置WithQueue时为nil
+	slowThreshold   time.Duration     // 带标签的任务执行时间超过该值时输出警告日志，为0时不检测
+	propagateKeys   []any             // 通过ScheduleCtx提交时从调用方ctx传递到任务ctx的值对应的key
+	deadlockTimeout time.Duration     // 工作池停滞超过该时间时告警，为0时不检测
+	limiter         *rateLimiter      // 任务分发限流器，为nil时不限速
+	clock           Clock             // 基于时间的逻辑使用的时钟，默认为系统时钟
 }
 
 const (
@@ -34,43 +213,197 @@ const (
 	maxCapacity     = 1000 // 最大容量
 )
 
-// New 创建一个新的工作池实例
+// poolSeq 用于为未命名的工作池生成名称
+var poolSeq atomic.Uint64
+
+// New 创建一个新的工作池实例，非法容量会被静默修正：负数使用默认容量，超过最大容量则截断为最大容量
+// 容量为0时工作池处于同步模式，任务在调用方协程中直接执行
+// 容量既可以通过位置参数capacity传入，也可以通过WithCapacity选项设置，两者同时给出时以选项为准
 func New(capacity int, opts ...Option) *Pool {
-	if capacity < 0 {
-		capacity = defaultCapacity
+	p := newPool(capacity, opts...)
+	p.capacity.Store(int64(validCapacity(int(p.capacity.Load()))))
+	p.queueSize = max(p.queueSize, 0)
+	p.synchronous = p.Cap() == 0
+	p.start()
+	return p
+}
+
+// NewWithError 创建一个新的工作池实例，与New不同，参数非法时返回错误而不是静默修正
+// 容量非法时返回ErrInvalidCapacity，选项取值非法或互相冲突时返回ErrInvalidOption，开启WithStartupProbe且探测失败时返回ErrStartupProbe
+func NewWithError(capacity int, opts ...Option) (*Pool, error) {
+	p := newPool(capacity, opts...)
+	if err := p.validate(); err != nil {
+		return nil, err
 	}
+	p.synchronous = p.Cap() == 0
+	p.start()
 
-	if capacity > maxCapacity {
-		capacity = defaultCapacity
+	if p.startupProbe && !p.synchronous {
+		if err := p.probe(); err != nil {
+			p.Free()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// validate 检查容量和选项的取值是否合法、选项之间是否兼容
+func (p *Pool) validate() error {
+	c := int(p.capacity.Load())
+	if c < 0 || c > maxCapacity {
+		return fmt.Errorf("%w: %d", ErrInvalidCapacity, c)
+	}
+
+	if p.preSpawn < 0 || p.preSpawn > c {
+		return fmt.Errorf("%w: preSpawn %d out of range [0, %d]", ErrInvalidOption, p.preSpawn, c)
 	}
+	if p.minWorkers < 0 || p.minWorkers > c {
+		return fmt.Errorf("%w: minWorkers %d out of range [0, %d]", ErrInvalidOption, p.minWorkers, c)
+	}
+	if p.queueSize < 0 {
+		return fmt.Errorf("%w: negative queueSize %d", ErrInvalidOption, p.queueSize)
+	}
+	if p.queueFullPolicy < PolicyBlock || p.queueFullPolicy > PolicyDropOldest {
+		return fmt.Errorf("%w: unknown queue full policy %d", ErrInvalidOption, p.queueFullPolicy)
+	}
+	if p.nonBlocking && p.queueFullPolicy == PolicyDropOldest {
+		return fmt.Errorf("%w: non-blocking mode conflicts with %s policy", ErrInvalidOption, p.queueFullPolicy)
+	}
+	if p.queueFullPolicy == PolicyDropOldest && p.queueSize == 0 {
+		return fmt.Errorf("%w: %s policy requires a buffered queue", ErrInvalidOption, p.queueFullPolicy)
+	}
+	if p.spawnStrategy < SpawnLazy || p.spawnStrategy > SpawnEager {
+		return fmt.Errorf("%w: unknown spawn strategy %d", ErrInvalidOption, p.spawnStrategy)
+	}
+	if p.queue != nil && p.queueSize > 0 {
+		return fmt.Errorf("%w: custom queue conflicts with queueSize %d", ErrInvalidOption, p.queueSize)
+	}
+	if p.fair != nil {
+		if err := p.fair.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewContext 创建一个生命周期与ctx绑定的工作池实例，ctx结束时工作池自动优雅关闭，参数含义与New一致
+func NewContext(ctx context.Context, capacity int, opts ...Option) *Pool {
+	p := New(capacity, opts...)
+
+	quit := p.quit
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Shutdown()
+		case <-quit:
+		}
+	}()
+
+	return p
+}
 
+// newPool 创建工作池结构体并应用可选配置，此时尚未启动任何协程
+func newPool(capacity int, opts ...Option) *Pool {
 	p := &Pool{
-		capacity: defaultCapacity,
-		active:   make(chan struct{}, capacity),
-		tasks:    make(chan Task),
-		wg:       sync.WaitGroup{},
-		quit:     make(chan struct{}),
+		initialized: true,
+		logger:      noopLogger{},
+		wg:          sync.WaitGroup{},
+		quit:        make(chan struct{}),
+		freed:       make(chan struct{}),
+		timers:      make(map[Timer]struct{}),
+		clock:       realClock{},
 	}
+	p.capacity.Store(int64(capacity))
 
 	// 应用可选配置
 	for _, opt := range opts {
 		opt(p)
 	}
 
-	fmt.Println("workerpool start")
+	if p.name == "" {
+		p.name = strconv.FormatUint(poolSeq.Add(1), 10)
+	}
+
+	return p
+}
+
+// start 按照配置分配通道并启动worker
+func (p *Pool) start() {
+	p.wakeup = make(chan struct{}, 1)
+	// 按提交顺序开始执行时不允许任务绕过队列交给新worker，spawn保持为nil，worker由run提前创建
+	if p.orderedStart {
+		p.startTurn = make(chan struct{}, 1)
+	} else {
+		p.spawn = make(chan Task)
+	}
+	p.urgent = make(chan Task)
+	p.prioSignal = make(chan struct{}, 1)
+	p.retire = make(chan struct{}, maxCapacity)
+	tasks := make(chan Task, max(p.queueSize, 0))
+	p.tasks.Store(&tasks)
+	if p.queue != nil {
+		p.custom = newCustomQueue(p.queue)
+	}
+
+	p.logf("workerpool start\n")
+
+	if p.onIdle != nil {
+		p.idleSignal = make(chan struct{}, 1)
+		p.wg.Add(1)
+		go p.watchIdle()
+	}
+
+	// 手动分发模式下不创建worker，也不启动run和分发协程，任务只在调用方调用Dispatch时执行
+	if p.manualDispatch {
+		p.watchSignals()
+		return
+	}
 
-	// 如果启用预分配，则预先创建指定数量的worker
+	// 如果启用预分配，则预先创建指定数量的worker，WithPreAlloc(true)相当于预创建全部容量
+	n := p.preSpawn
 	if p.preAlloc {
-		for i := 1; i <= p.capacity; i++ {
-			p.newWorker(i)
-			p.active <- struct{}{}
+		n = p.Cap()
+	}
+	n = min(max(n, p.minWorkers), p.Cap())
+
+	if p.rampUp > 0 && n > 1 {
+		// 第一个worker立即创建，其余的在后台按rampUp均匀错开创建
+		if p.reserveWorker() {
+			p.newWorker(nil)
+		}
+		p.wg.Add(1)
+		go p.rampUpWorkers(n - 1)
+	} else {
+		for i := 0; i < n && p.reserveWorker(); i++ {
+			p.newWorker(nil)
 		}
 	}
 
-	// 启动工作池运行协程
+	// 启动工作池运行协程和分发协程，run同样计入wg，保证Free等待期间不会再有新的worker被创建
+	p.wg.Add(2)
 	go p.run()
+	go p.dispatch()
 
-	return p
+	if p.deadlockTimeout > 0 && !p.synchronous {
+		p.wg.Add(1)
+		go p.watchDeadlock()
+	}
+
+	p.watchSignals()
+}
+
+// validCapacity 校验容量：负数使用默认容量，超过最大容量则截断为最大容量
+func validCapacity(capacity int) int {
+	if capacity < 0 {
+		return defaultCapacity
+	}
+
+	if capacity > maxCapacity {
+		return maxCapacity
+	}
+
+	return capacity
 }
 
 // WithPreAlloc 设置是否预分配worker的选项
@@ -80,63 +413,1545 @@ func WithPreAlloc(b bool) Option {
 	}
 }
 
-// run 运行工作池主循环，动态创建worker
+// WithRampUp 设置预创建worker错开时间的选项，默认同时创建
+// 设置后通过WithPreAlloc、WithPreSpawn或WithMinWorkers预创建的worker在d时间内均匀错开创建，避免启动时集中冲击下游资源
+// 第一个worker在创建工作池时立即创建；错开期间有任务积压时run仍会按需创建worker，SpawnEager策略下错开不生效
+func WithRampUp(d time.Duration) Option {
+	return func(p *Pool) {
+		p.rampUp = d
+	}
+}
+
+// rampUpWorkers 在rampUp时间内每隔相同的间隔创建一个worker，共创建n个，工作池被释放或容量已满时停止
+func (p *Pool) rampUpWorkers(n int) {
+	defer p.wg.Done()
+
+	interval := p.rampUp / time.Duration(n)
+	timer := p.clock.NewTimer(interval)
+	defer timer.Stop()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-p.quit:
+			return
+		case <-timer.C():
+		}
+		timer.Reset(interval)
+
+		if p.frozen.Load() {
+			continue
+		}
+		if !p.reserveWorker() {
+			return
+		}
+		p.newWorker(nil)
+	}
+}
+
+// WithPreSpawn 设置创建pool时预创建worker数量的选项，其余容量仍按需创建
+// n超过容量时按容量预创建，NewWithError则返回ErrInvalidOption；与WithPreAlloc(true)同时使用时以WithPreAlloc为准，预创建全部容量
+func WithPreSpawn(n int) Option {
+	return func(p *Pool) {
+		p.preSpawn = n
+	}
+}
+
+// WithCapacity 设置工作池容量的选项，校验规则与New/NewWithError的capacity参数一致
+func WithCapacity(n int) Option {
+	return func(p *Pool) {
+		p.capacity.Store(int64(n))
+	}
+}
+
+// WithQueueSize 设置任务队列缓冲区大小的选项
+// 设置后最多可以有n个任务在队列中等待，Schedule只有在队列已满且所有worker都忙碌时才会阻塞
+func WithQueueSize(n int) Option {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// WithMaxBlocking 设置允许同时阻塞在Schedule中的调用方数量上限的选项
+// 已有n个调用方阻塞等待时，新的Schedule调用立即返回ErrPoolOverloaded，n不大于0时不限制
+func WithMaxBlocking(n int) Option {
+	return func(p *Pool) {
+		p.maxBlocking = n
+	}
+}
+
+// WithNonBlocking 设置非阻塞模式的选项
+// 开启后Schedule等提交方法的行为与TrySchedule一致：没有空闲worker且任务队列已满时立即返回ErrPoolBusy
+func WithNonBlocking(b bool) Option {
+	return func(p *Pool) {
+		p.nonBlocking = b
+	}
+}
+
+// WithIdleTimeout 设置worker空闲超时时间的选项
+// worker等待任务超过d后退出并释放其占用的容量，之后有任务到来时由run按需重新创建
+func WithIdleTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// WithWorkerMaxTasks 设置每个worker最多执行任务数量的选项，默认不限制
+// worker执行完成n个任务后主动退出，之后有任务到来时由run按需创建新的worker，用于定期回收worker协程积累的状态
+// n不大于0时不限制
+func WithWorkerMaxTasks(n int) Option {
+	return func(p *Pool) {
+		p.maxTasks = uint64(max(n, 0))
+	}
+}
+
+// WithOverflowPool 设置溢出工作池的选项，默认不设置
+// 设置后Schedule在当前工作池无法立即接收任务时不再阻塞，而是将任务转交给other，是否阻塞由other的配置决定
+// 转交的任务计入other的统计，不计入当前工作池；other为nil时不转交
+func WithOverflowPool(other *Pool) Option {
+	return func(p *Pool) {
+		p.overflow = other
+	}
+}
+
+// WithOrderedStart 设置是否保证任务按提交顺序开始执行的选项，默认关闭
+// 开启后同一时刻只有一个worker从任务队列取任务，并在任务开始执行后才交出执行权，任务仍然可以乱序完成
+// 取任务和开始执行的步骤被串行化，节流或钩子较慢时吞吐量明显下降；worker始终保持与容量相同的数量，空闲超时不生效
+// 顺序只对通过任务队列提交的任务有效，优先级任务以及释放工作池时兜底执行的任务不保证顺序
+func WithOrderedStart(b bool) Option {
+	return func(p *Pool) {
+		p.orderedStart = b
+	}
+}
+
+// WithMinWorkers 设置最小保留worker数量的选项，默认值为：0
+// 创建pool时至少预创建n个worker，空闲超时不会使存活worker数量低于n；n不能超过容量，否则NewWithError返回ErrInvalidOption
+// 通过Tune缩容到n以下时仍以容量为准
+func WithMinWorkers(n int) Option {
+	return func(p *Pool) {
+		p.minWorkers = n
+	}
+}
+
+// WithHooks 设置任务执行钩子的选项，start在任务开始执行前调用，complete在任务执行结束后调用
+// complete的参数为任务panic的值，任务正常返回时为nil；任务panic时两个钩子同样会被调用
+func WithHooks(start func(), complete func(recovered any)) Option {
+	return func(p *Pool) {
+		p.onStart = start
+		p.onComplete = complete
+	}
+}
+
+// WithWorkerInit 设置worker初始化函数的选项
+// init在每个worker启动时、处理任务之前调用一次，可用于分配worker独享的资源，返回的清理函数在该worker退出时调用
+func WithWorkerInit(init func(id int) func()) Option {
+	return func(p *Pool) {
+		p.workerInit = init
+	}
+}
+
+// WithOnShutdown 设置工作池释放时调用的清理函数的选项，可用于释放与工作池生命周期绑定的共享资源
+// fn在Free、Shutdown等释放流程中所有worker退出、兜底任务执行完成之后调用，重复释放时不会再次调用；通过Reset重新使用的工作池再次释放时会再次调用
+// fn返回后释放流程才结束，其中不能再调用当前工作池的Free等等待释放完成的方法
+func WithOnShutdown(fn func()) Option {
+	return func(p *Pool) {
+		p.onShutdown = fn
+	}
+}
+
+// WithLockOSThread 设置worker是否独占操作系统线程的选项，默认值为：false
+// 开启后每个worker启动时调用runtime.LockOSThread，退出时解除，worker的初始化函数和任务都在该worker独占的线程上执行，
+// 适用于依赖线程状态的CGO调用等场景；每个存活的worker都会占用一个线程，
+// 任务中的阻塞系统调用只会阻塞该线程，运行时需要为其他协程另起线程，容量较大时应注意线程数量
+func WithLockOSThread(b bool) Option {
+	return func(p *Pool) {
+		p.lockOSThread = b
+	}
+}
+
+// WithPanicHandler 设置任务panic处理函数的选项，处理函数接收recover得到的值和panic发生时的调用栈
+// 未设置时panic信息通过日志输出，日志中只包含recover得到的值
+func WithPanicHandler(h func(recovered any, stack []byte)) Option {
+	return func(p *Pool) {
+		p.panicHandler = h
+	}
+}
+
+// WithRepanicFilter 设置重新抛出任务panic的过滤函数的选项，默认恢复所有panic
+// 任务panic并经过panic处理函数处理后，filter返回true时worker重新抛出该panic，进程随之崩溃
+// 用于区分预期内的任务错误与需要立即暴露的程序错误，例如只对runtime.Error返回true
+func WithRepanicFilter(filter func(recovered any) bool) Option {
+	return func(p *Pool) {
+		p.repanic = filter
+	}
+}
+
+// WithRecover 设置worker是否捕获任务panic的选项，默认值为：true
+// 关闭后任务panic不会被捕获，与普通协程一样带着完整的调用栈使进程崩溃，用于开发调试；panic处理函数、重新抛出过滤函数均不再生效
+// Submit、Group等把任务panic转换为错误返回给调用方的方法自行捕获panic，不受该选项影响
+func WithRecover(b bool) Option {
+	return func(p *Pool) {
+		p.noRecover = !b
+	}
+}
+
+// run 运行工作池主循环，按照创建策略动态创建worker
+// SpawnLazy策略下仅在有任务等待且没有空闲worker、存活worker数量低于容量时才创建，worker数量与实际负载成正比；SpawnEager策略下主动创建到容量
+// 创建worker前先通过reserveWorker预留名额，worker真正退出时才释放，保证任何时刻存活的worker协程数量都不超过容量
 func (p *Pool) run() {
-	id := 0
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		default:
+		}
+
+		var spawn chan Task
+		if p.workers.Load() < p.capacity.Load() && !p.frozen.Load() {
+			// 主动创建策略下直接补足容量，否则只在任务积压时创建
+			if (p.keepFull() || p.backlogged()) && p.reserveWorker() {
+				p.newWorker(nil)
+				continue
+			}
+			spawn = p.spawn
+		}
+
+		select {
+		case <-p.quit:
+			return
+		case <-p.wakeup:
+		case t := <-spawn:
+			if !p.frozen.Load() && p.reserveWorker() {
+				p.newWorker(t)
+				continue
+			}
+			// 等待期间容量被缩小或worker的创建被冻结，交还给存活的worker执行
+			p.requeue(t)
+		}
+	}
+}
+
+// requeue 将run无法为其创建worker的任务放回任务队列
+// 工作池退出时若为优雅关闭则直接执行，否则与队列中的其他任务一样被丢弃
+func (p *Pool) requeue(t Task) {
+	// 与提交方一样持有读锁，避免任务队列在交付期间被ResizeQueue替换
+	p.acceptMu.RLock()
+	defer p.acceptMu.RUnlock()
+
+	quit := p.quit
 	for {
+		y := p.acceptYield()
 		select {
+		case p.taskChan() <- t:
+			return
 		case <-p.quit:
+			if p.graceful.Load() {
+				p.execute(0, t)
+			} else {
+				p.donePending()
+			}
 			return
-		case p.active <- struct{}{}:
-			id++
-			p.newWorker(id)
+		case <-y.preempt:
+			if !p.yieldAccept(y, quit) {
+				return
+			}
+		}
+	}
+}
+
+// reserveWorker 在存活worker数量低于容量时为新worker预留一个名额
+func (p *Pool) reserveWorker() bool {
+	for {
+		n := p.workers.Load()
+		if n >= p.capacity.Load() {
+			return false
 		}
+		if p.workers.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// backlogged 返回任务队列中是否有任务在等待且没有空闲的worker
+func (p *Pool) backlogged() bool {
+	return p.QueueLen() > 0 && p.workers.Load() <= p.running.Load()
+}
+
+// notify 非阻塞地通知run重新检查worker数量
+func (p *Pool) notify() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
 	}
 }
 
-// newWorker 创建一个新的工作协程
-func (p *Pool) newWorker(id int) {
+// newWorker 创建一个新的工作协程，调用方需要事先通过reserveWorker预留名额
+// first不为nil时worker启动后首先执行first；worker的编号从可复用的编号中分配，退出时归还
+func (p *Pool) newWorker(first Task) {
+	id := p.ids.get()
+	ws := p.registerWorker(id)
+
 	p.wg.Add(1)
 	go func() {
+		// 所有退出路径都经过这里，保证worker计数与存活的worker协程一一对应
+		// 因缩容或空闲超时退出时计数已在tryRetire或tryIdleExit中扣减；先归还编号再释放名额，保证新worker总能复用编号
+		// 退出事件在归还编号之前发出，避免复用该编号的新worker的启动事件早于它，使同一编号看起来同时存活
+		retired := false
 		defer func() {
-			// 捕获可能的panic并优雅地退出
-			if err := recover(); err != nil {
-				fmt.Printf("worker[%d] recover panic[%s] and exit\n", id, err)
-				<-p.active
+			p.emit(EventWorkerExit, id, nil)
+			p.unregisterWorker(id)
+			p.ids.put(id)
+			if !retired {
+				p.workers.Add(-1)
 			}
+			p.notify()
 			p.wg.Done()
 		}()
 
-		fmt.Printf("worker[%d] start\n", id)
+		p.logf("worker[%d] start\n", id)
+		p.emit(EventWorkerStart, id, nil)
+		defer p.markWorker(ws)()
+
+		if p.lockOSThread {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+		}
+
+		if p.workerInit != nil {
+			var cleanup func()
+			p.callHook(id, func() { cleanup = p.workerInit(id) })
+			if cleanup != nil {
+				defer p.callHook(id, cleanup)
+			}
+		}
+
+		if first != nil {
+			p.waitUnpaused()
+			p.waitUnits()
+			p.debugf("worker[%d] receive a task\n", id)
+			p.emit(EventTaskReceived, id, nil)
+			p.execute(id, first)
+			if ws.count(1, p.maxTasks) {
+				p.logf("worker[%d] reached max tasks, exit\n", id)
+				return
+			}
+		}
+
+		// 配置了空闲超时时，worker等待任务超过该时间后退出
+		var timer Timer
+		var idle <-chan time.Time
+		if p.idleTimeout > 0 && !p.keepFull() {
+			timer = p.clock.NewTimer(p.idleTimeout)
+			defer timer.Stop()
+			idle = timer.C()
+		}
+
+		// holding表示当前worker持有按提交顺序开始执行的执行权，退出时需要交还
+		holding := false
+		defer func() {
+			if holding {
+				p.releaseTurn()
+			}
+		}()
 
 		// 工作协程主循环
 		for {
+			if timer != nil {
+				timer.Reset(p.idleTimeout)
+			}
+
+			// 加权任务占满并发单位时暂不取任务，等待单位释放
+			urgent, tasks, queued, inbox := p.urgent, p.taskChan(), p.queueReady(), ws.inbox
+			gate := p.weightGate()
+			if gate != nil {
+				urgent, tasks, queued, inbox = nil, nil, nil, nil
+			}
+
+			// 按提交顺序开始执行时，先取得执行权才能从任务队列取任务
+			var turn chan struct{}
+			if p.orderedStart && !holding {
+				turn, tasks, queued, inbox = p.startTurn, nil, nil, nil
+			}
+
+			// 暂停期间不取任务，等待Unpause
+			paused := p.pauseGate()
+			if paused != nil {
+				urgent, tasks, queued, inbox, turn = nil, nil, nil, nil, nil
+			}
+
+			// 优先处理分发协程发来的优先级任务
+			var t Task
 			select {
-			case <-p.quit:
-				fmt.Printf("worker[%d] exit\n", id)
-				<-p.active
+			case t = <-urgent:
+			default:
+				select {
+				case <-p.quit:
+					if p.graceful.Load() {
+						ws.handled.Add(uint64(p.drain(id)))
+					}
+					p.logf("worker[%d] exit\n", id)
+					return
+				case <-idle:
+					// 存活worker数量不高于最小保留数量时继续等待
+					if p.tryIdleExit() {
+						p.logf("worker[%d] idle exit\n", id)
+						retired = true
+						return
+					}
+				case <-p.retire:
+				case <-ws.signal:
+					ws.ackFlush()
+				case <-gate:
+				case <-paused:
+				case turn <- struct{}{}:
+					holding = true
+				case t = <-urgent:
+				case t = <-inbox:
+				case t = <-tasks:
+					if len(p.taskChan()) > 0 {
+						p.notify()
+					}
+				case <-queued:
+					// 其他worker可能先取走了任务，此时t为nil，继续等待
+					t = p.popQueue()
+					if p.QueueLen() > 0 {
+						p.notify()
+					}
+				}
+			}
+
+			if t != nil && holding {
+				holding = false
+				t = p.inTurn(t)
+			}
+
+			if t != nil {
+				p.debugf("worker[%d] receive a task\n", id)
+				p.emit(EventTaskReceived, id, nil)
+				p.execute(id, t)
+				if ws.count(1, p.maxTasks) {
+					p.logf("worker[%d] reached max tasks, exit\n", id)
+					return
+				}
+			}
+
+			// 缩容后多余的worker在完成当前任务后退出
+			if p.tryRetire() {
+				p.logf("worker[%d] retire\n", id)
+				retired = true
 				return
-			case t := <-p.tasks:
-				fmt.Printf("worker[%d] receive a task\n", id)
-				t()
 			}
 		}
 	}()
 }
 
-// Free 释放工作池资源，等待所有worker退出
-func (p *Pool) Free() {
-	close(p.quit)
-	p.wg.Wait()
-	fmt.Printf("workerpool free\n")
+// tryRetire 存活worker数量超过容量时，扣减worker计数并返回true，表示当前worker应当退出
+func (p *Pool) tryRetire() bool {
+	for {
+		n := p.workers.Load()
+		if n <= p.capacity.Load() {
+			return false
+		}
+		if p.workers.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
 }
 
-// Schedule 提交一个任务到工作池执行
-func (p *Pool) Schedule(t Task) error {
-	select {
-	case <-p.quit:
-		return ErrWorkerPoolFreed
-	case p.tasks <- t:
-		return nil
+// tryIdleExit 在worker空闲超时后调用，存活worker数量高于最小保留数量时扣减计数并返回true
+func (p *Pool) tryIdleExit() bool {
+	for {
+		n := p.workers.Load()
+		if n <= int64(p.minWorkers) {
+			return false
+		}
+		if p.workers.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// execute 在当前worker中执行任务，任务panic时被捕获，worker继续处理后续任务
+func (p *Pool) execute(id int, t Task) {
+	p.throttle()
+
+	p.checkSaturated(id, p.running.Add(1))
+	p.notifyRunning()
+	defer p.completePending()
+	defer p.completed.Add(1)
+	defer p.doneRunning(id)
+
+	p.runTask(id, t)
+}
+
+// runTask 执行t并完成单个任务的钩子调用、panic处理和耗时统计，不涉及Running和完成数量的计数
+func (p *Pool) runTask(id int, t Task) {
+	defer func() {
+		// 捕获可能的panic，避免worker退出导致工作池缩容；关闭捕获时panic继续向上传播
+		var err any
+		if !p.noRecover {
+			err = recover()
+		}
+		if p.onComplete != nil {
+			p.callHook(id, func() { p.onComplete(err) })
+		}
+		if err != nil {
+			p.panics.Add(1)
+			p.handlePanic(id, err, debug.Stack())
+			p.checkRepanic(err)
+		}
+	}()
+
+	if p.onStart != nil {
+		p.onStart()
 	}
+
+	start := p.clock.Now()
+	defer func() { p.latency.record(p.clock.Now().Sub(start)) }()
+	t()
+}
+
+// runChained 在执行外层任务的worker上接着执行一个排队的任务，用于key和Limiter的等待队列
+// 与worker主循环一样发出事件、调用钩子、统计耗时并计入该worker和工作池的完成数量；当前worker已计入Running，不重复计数
+func (p *Pool) runChained(t Task) {
+	ws := p.currentWorker()
+	id := 0
+	if ws != nil {
+		id = ws.id
+	}
+
+	p.emit(EventTaskReceived, id, nil)
+	p.runTask(id, t)
+	p.completed.Add(1)
+	p.completePending()
+	if ws != nil {
+		ws.count(1, 0)
+	}
+}
+
+// callHook 调用钩子函数，钩子自身的panic会被捕获并记录日志
+func (p *Pool) callHook(id int, hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logf("worker[%d] recover panic[%v] from hook\n", id, r)
+		}
+	}()
+	hook()
+}
+
+// checkRepanic 在任务panic被处理后调用，过滤函数返回true时重新抛出
+func (p *Pool) checkRepanic(err any) {
+	if p.repanic != nil && p.repanic(err) {
+		panic(err)
+	}
+}
+
+// handlePanic 处理任务panic，stack为在recover处捕获的调用栈
+// panic处理函数自身发生的panic同样会被捕获，避免worker协程意外退出
+func (p *Pool) handlePanic(id int, err any, stack []byte) {
+	p.emit(EventTaskPanic, id, err)
+
+	if p.panicHandler == nil {
+		p.logf("worker[%d] recover panic[%v]\n", id, err)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.logf("worker[%d] recover panic[%v] from panic handler\n", id, r)
+		}
+	}()
+	p.panicHandler(err, stack)
+}
+
+// drain 执行优先级队列和任务队列中剩余的任务，直到队列为空，返回执行的任务数量
+func (p *Pool) drain(id int) (n int) {
+	for ; ; n++ {
+		if item, ok := p.popPriority(); ok {
+			t := item.task
+			item.release()
+			p.execute(id, t)
+			continue
+		}
+		if t := p.popQueue(); t != nil {
+			p.execute(id, t)
+			continue
+		}
+
+		select {
+		case t := <-p.taskChan():
+			// 任务队列被ResizeQueue替换后，旧的通道关闭时接收到nil，重新读取新的通道
+			if t == nil {
+				n--
+				continue
+			}
+			p.execute(id, t)
+		default:
+			return n
+		}
+	}
+}
+
+// Free 立即释放工作池资源，等待所有worker退出，队列中尚未执行的任务会被丢弃
+// Free可以被重复或并发调用，只有第一次调用会真正释放资源，其余调用会等待释放完成后直接返回
+// 工作池未通过New等构造函数创建时没有效果
+func (p *Pool) Free() {
+	p.release(false)
+}
+
+// Shutdown 优雅关闭工作池：不再接收新任务，等待队列中已有的任务全部执行完成后再释放资源
+// Shutdown与Free共享同一释放流程，两者中先调用的决定关闭方式
+func (p *Pool) Shutdown() {
+	p.release(true)
+}
+
+// ShutdownTimeout 优雅关闭工作池，最多等待d时间
+// 超时仍有worker未退出时返回ErrShutdownTimeout，关闭流程会在后台继续进行直至所有worker退出
+func (p *Pool) ShutdownTimeout(d time.Duration) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Shutdown()
+		close(done)
+	}()
+
+	timer := p.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C():
+		return ErrShutdownTimeout
+	}
+}
+
+// FreeContext 优雅关闭工作池，最多等待到ctx结束，行为与Shutdown一致
+// ctx在所有worker退出前结束时返回ctx.Err()，关闭流程会在后台继续进行直至所有worker退出
+// 工作池未通过New等构造函数创建时返回ErrPoolNotInitialized
+func (p *Pool) FreeContext(ctx context.Context) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 立即释放工作池资源，行为与Free一致，实现了io.Closer接口
+// 工作池已被释放时返回ErrWorkerPoolFreed，释放后仍有worker存活等内部状态不一致时返回ErrInvariantViolated
+// 工作池未通过New等构造函数创建时返回ErrPoolNotInitialized
+func (p *Pool) Close() error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if !p.release(false) {
+		return ErrWorkerPoolFreed
+	}
+
+	if n := p.workers.Load(); n != 0 {
+		return fmt.Errorf("%w: %d workers alive after free", ErrInvariantViolated, n)
+	}
+	if n := p.Running(); n != 0 {
+		return fmt.Errorf("%w: %d tasks running after free", ErrInvariantViolated, n)
+	}
+
+	return nil
+}
+
+// release 关闭工作池并等待所有worker退出，graceful为true时先执行完队列中的任务
+// 返回本次调用是否真正执行了释放流程
+func (p *Pool) release(graceful bool) (released bool) {
+	if !p.isInitialized() {
+		return false
+	}
+
+	p.freeOnce.Do(func() {
+		released = true
+
+		p.graceful.Store(graceful)
+
+		p.state.Store(int32(StateShuttingDown))
+		close(p.quit)
+
+		// 等待已通过检查的提交方完成交付，阻塞中的提交方会因quit关闭而返回
+		// 此后任务队列中不会再出现新任务，下面的兜底执行不会遗漏任何已被接收的任务
+		p.acceptMu.Lock()
+		p.acceptMu.Unlock()
+		p.stopTimers()
+		p.stopSignals()
+
+		p.wg.Wait()
+
+		// worker全部退出后，兜底执行退出过程中才进入队列的任务
+		if graceful {
+			p.drain(0)
+		}
+
+		if p.onShutdown != nil {
+			p.callHook(0, p.onShutdown)
+		}
+
+		p.state.Store(int32(StateFreed))
+		close(p.freed)
+		p.logf("workerpool free\n")
+	})
+
+	return released
+}
+
+// Reset 重新初始化已释放的工作池，使其可以再次接收任务
+// 只能在Free返回之后调用，否则返回ErrPoolNotFreed；除Schedule等提交方法外，Reset不能与工作池的其他方法并发调用
+// 重新初始化期间工作池处于StateResetting状态，并发的提交方法返回ErrPoolResetting，不会访问正在重新分配的通道
+// 释放时仍在队列中未执行的任务会被丢弃
+func (p *Pool) Reset() error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if !p.state.CompareAndSwap(int32(StateFreed), int32(StateResetting)) {
+		return ErrPoolNotFreed
+	}
+
+	// 持有写锁期间已通过检查的提交方全部退出，之后进入的提交方在检查中看到StateResetting或重新初始化后的工作池
+	p.acceptMu.Lock()
+	defer p.acceptMu.Unlock()
+
+	p.quit = make(chan struct{})
+	p.freed = make(chan struct{})
+	p.freeOnce = sync.Once{}
+
+	p.prioMu.Lock()
+	p.prioQueue = nil
+	if p.fair != nil {
+		p.fair.reset()
+	}
+	p.prioMu.Unlock()
+
+	// 释放时被丢弃的任务不会再执行，清除它们占用的key
+	p.onceMu.Lock()
+	p.onceKeys = nil
+	p.onceMu.Unlock()
+
+	p.keyedMu.Lock()
+	p.keyed = nil
+	p.keyedMu.Unlock()
+	p.dropHooks.Clear()
+
+	p.replaceMu.Lock()
+	p.replacing = nil
+	p.replaceMu.Unlock()
+
+	p.affinityMu.Lock()
+	p.affinity = nil
+	p.affinityMu.Unlock()
+
+	// 被丢弃的任务不会再扣除大小，直接清零
+	p.bytesMu.Lock()
+	p.inFlightBytes.Store(0)
+	p.bytesMu.Unlock()
+
+	// 自定义任务队列中未执行的任务同样被丢弃
+	for p.popQueue() != nil {
+	}
+
+	p.pendingMu.Lock()
+	p.pending = 0
+	p.idleArmed = false
+	if p.idle != nil {
+		close(p.idle)
+		p.idle = nil
+	}
+	p.pendingMu.Unlock()
+
+	p.draining.Store(false)
+	p.state.Store(int32(StateRunning))
+	p.start()
+	return nil
+}
+
+// Schedule 提交一个任务到工作池执行
+func (p *Pool) Schedule(t Task) error {
+	return p.ScheduleWithContext(context.Background(), t)
+}
+
+// MustSchedule 提交一个任务到工作池执行，提交失败时panic，适用于提交失败无法恢复的初始化代码和测试
+func (p *Pool) MustSchedule(t Task) {
+	if err := p.Schedule(t); err != nil {
+		panic(fmt.Sprintf("workerpool: schedule failed: %v", err))
+	}
+}
+
+// ScheduleWithContext 提交一个任务到工作池执行，ctx在任务被接收前结束时返回ctx.Err()
+func (p *Pool) ScheduleWithContext(ctx context.Context, t Task) error {
+	_, err := p.schedule(ctx, t)
+	return err
+}
+
+// schedule 实现ScheduleWithContext，queued表示任务是否通过任务队列通道交付，为false时任务交给run新创建的worker执行
+func (p *Pool) schedule(ctx context.Context, t Task) (queued bool, err error) {
+	if !p.isInitialized() {
+		return false, ErrPoolNotInitialized
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	if p.synchronous {
+		return false, p.runInline(t)
+	}
+
+	if p.overflow != nil {
+		queued, err := p.trySchedule(t)
+		if errors.Is(err, ErrPoolBusy) {
+			return false, p.overflow.ScheduleWithContext(ctx, t)
+		}
+		return queued, err
+	}
+
+	if p.nonBlocking || p.queueFullPolicy == PolicyReject {
+		return p.trySchedule(t)
+	}
+
+	return p.scheduleWait(ctx, t)
+}
+
+// scheduleWait 阻塞地交付任务，直到任务被接收、ctx结束或工作池被释放，不受非阻塞模式和拒绝策略的影响
+func (p *Pool) scheduleWait(ctx context.Context, t Task) (queued bool, err error) {
+	if err := p.enterSchedule(); err != nil {
+		return false, err
+	}
+	defer p.exitSchedule()
+	t = p.afterBarrier(t)
+
+	if p.custom != nil {
+		p.pushQueue(t)
+		return true, nil
+	}
+
+	// 优先交给空闲worker或放入任务队列
+	select {
+	case p.taskChan() <- t:
+		p.accepted()
+		return true, nil
+	default:
+	}
+
+	if p.queueFullPolicy == PolicyDropOldest && cap(p.taskChan()) > 0 {
+		queued, err := p.scheduleDropOldest(t)
+		if err != nil {
+			p.donePending()
+			return false, err
+		}
+		p.accepted()
+		return queued, nil
+	}
+
+	// 需要阻塞等待时，检查阻塞中的调用方数量是否已达上限
+	if p.maxBlocking > 0 {
+		if p.blocking.Add(1) > int64(p.maxBlocking) {
+			p.blocking.Add(-1)
+			p.donePending()
+			p.rejected.Add(1)
+			return false, ErrPoolOverloaded
+		}
+		defer p.blocking.Add(-1)
+	}
+
+	quit := p.quit
+	for {
+		y := p.acceptYield()
+		select {
+		case <-ctx.Done():
+			p.donePending()
+			return false, ctx.Err()
+		case <-p.quit:
+			p.donePending()
+			return false, ErrWorkerPoolFreed
+		case p.taskChan() <- t:
+			p.accepted()
+			return true, nil
+		case p.spawn <- t:
+			p.accepted()
+			return false, nil
+		case <-y.preempt:
+			// 有调用方等待写锁，让出读锁后重新读取可能已被替换的任务队列
+			if !p.yieldAccept(y, quit) {
+				return false, ErrWorkerPoolFreed
+			}
+		}
+	}
+}
+
+// ScheduleTimeout 提交一个任务到工作池执行，d时间内任务仍未被接收时返回ErrScheduleTimeout
+func (p *Pool) ScheduleTimeout(t Task, d time.Duration) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	// 通过工作池的时钟计时，超时后以ErrScheduleTimeout为原因取消ctx
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	timer := p.clock.AfterFunc(d, func() { cancel(ErrScheduleTimeout) })
+	defer timer.Stop()
+
+	err := p.ScheduleWithContext(ctx, t)
+	if errors.Is(err, context.Canceled) {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// TrySchedule 尝试提交一个任务到工作池执行，不会阻塞
+// 当前没有worker可以立即接收任务且任务队列已满时返回ErrPoolBusy
+func (p *Pool) TrySchedule(t Task) error {
+	_, err := p.trySchedule(t)
+	return err
+}
+
+// trySchedule 实现TrySchedule，queued的含义与schedule相同
+func (p *Pool) trySchedule(t Task) (queued bool, err error) {
+	if !p.isInitialized() {
+		return false, ErrPoolNotInitialized
+	}
+	if p.synchronous {
+		return false, p.runInline(t)
+	}
+
+	if err := p.enterSchedule(); err != nil {
+		return false, err
+	}
+	defer p.exitSchedule()
+	t = p.afterBarrier(t)
+
+	if p.custom != nil {
+		p.pushQueue(t)
+		return true, nil
+	}
+
+	select {
+	case p.taskChan() <- t:
+		p.accepted()
+		return true, nil
+	case p.spawn <- t:
+		p.accepted()
+		return false, nil
+	default:
+		p.donePending()
+		p.rejected.Add(1)
+		return false, ErrPoolBusy
+	}
+}
+
+// SchedulePos 提交一个任务到工作池执行，返回任务进入任务队列时排在它前面的任务数量
+// 任务被空闲worker直接接收或交给新创建的worker时返回0；阻塞和错误的语义与Schedule相同
+// 位置在交付完成后根据QueueLen计算，不包括优先级任务，并发提交时只是近似值
+func (p *Pool) SchedulePos(t Task) (pos int, err error) {
+	queued, err := p.schedule(context.Background(), t)
+	if err != nil || !queued {
+		return 0, err
+	}
+
+	// 任务被直接交给等待中的worker时不会进入缓冲区，此时队列为空
+	return max(p.QueueLen()-1, 0), nil
+}
+
+// accepted 在任务被工作池接收后调用，累计提交计数并通知run检查是否有任务积压
+func (p *Pool) accepted() {
+	p.submitted.Add(1)
+	p.updateHighWater()
+	p.notify()
+}
+
+// ScheduleBatch 依次提交一批任务，遇到第一个失败即停止，返回已成功提交的任务数量及失败原因
+func (p *Pool) ScheduleBatch(tasks []Task) (accepted int, err error) {
+	return p.ScheduleBatchWithContext(context.Background(), tasks)
+}
+
+// ScheduleBatchWithContext 与ScheduleBatch相同，ctx结束时停止提交剩余任务
+func (p *Pool) ScheduleBatchWithContext(ctx context.Context, tasks []Task) (accepted int, err error) {
+	for _, t := range tasks {
+		if err = p.ScheduleWithContext(ctx, t); err != nil {
+			return accepted, err
+		}
+		accepted++
+	}
+
+	return accepted, nil
+}
+
+// ScheduleAll 依次提交tasks中的所有任务，阻塞直到全部任务都被接收，用于不能丢弃任务的提交方
+// 与ScheduleBatch不同，非阻塞模式或拒绝策略下同样阻塞等待worker接收；阻塞的调用方数量达到上限时等待有空闲容量后重试
+// 只有ctx结束或工作池被释放时提前返回，返回的错误包装了原因及尚未提交的任务数量
+func (p *Pool) ScheduleAll(ctx context.Context, tasks []Task) error {
+	for i, t := range tasks {
+		if err := p.scheduleBlocking(ctx, t); err != nil {
+			return fmt.Errorf("%d of %d tasks not scheduled: %w", len(tasks)-i, len(tasks), err)
+		}
+	}
+
+	return nil
+}
+
+// ScheduleStream 不断从in读取任务并提交到工作池，提交时阻塞等待worker接收，以此向生产者施加背压
+// 提交的方式与ScheduleAll相同，不受非阻塞模式和拒绝策略的影响；in被关闭时返回nil
+// ctx结束时返回ctx.Err()，工作池被释放时返回ErrWorkerPoolFreed，提交失败时返回提交错误，此时已从in读出的任务不会执行
+func (p *Pool) ScheduleStream(ctx context.Context, in <-chan Task) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	for {
+		select {
+		case t, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := p.scheduleBlocking(ctx, t); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.quit:
+			return ErrWorkerPoolFreed
+		}
+	}
+}
+
+// scheduleBlocking 阻塞地提交一个任务，直到任务被接收、ctx结束或工作池被释放
+// 阻塞的调用方数量达到上限时等待有空闲容量后重试
+func (p *Pool) scheduleBlocking(ctx context.Context, t Task) error {
+	for {
+		err := ctx.Err()
+		if err == nil {
+			if p.synchronous {
+				err = p.runInline(t)
+			} else {
+				_, err = p.scheduleWait(ctx, t)
+			}
+		}
+
+		if errors.Is(err, ErrPoolOverloaded) {
+			if err = p.WaitAvailable(ctx); err == nil {
+				// 让出处理器，避免空闲容量被阻塞中的调用方占用时空转
+				runtime.Gosched()
+				continue
+			}
+		}
+		return err
+	}
+}
+
+// Running 返回当前正在执行的任务数量，O(1)且不加锁，可以在高频提交时频繁调用
+// 计数只在任务真正开始执行时增加、结束时减少，不包括已交给worker但仍在等待节流的任务；同步模式、ScheduleReturn等在调用方协程中执行的任务同样计入
+func (p *Pool) Running() int {
+	if !p.isInitialized() {
+		return 0
+	}
+	return int(p.running.Load())
+}
+
+// Available 返回当前空闲的容量，即容量减去正在执行任务的worker数量
+// 由于Free已用于释放工作池，这里没有沿用Free作为方法名
+func (p *Pool) Available() int {
+	return p.Cap() - p.Running()
+}
+
+// SubmittedCount 返回工作池累计成功提交的任务数量
+func (p *Pool) SubmittedCount() uint64 {
+	if !p.isInitialized() {
+		return 0
+	}
+	return p.submitted.Load()
+}
+
+// CompletedCount 返回工作池累计执行完成的任务数量，包括panic的任务
+func (p *Pool) CompletedCount() uint64 {
+	if !p.isInitialized() {
+		return 0
+	}
+	return p.completed.Load()
+}
+
+// RejectedCount 返回工作池累计因容量不足被拒绝或丢弃的任务数量
+// 包括返回ErrPoolBusy、ErrPoolOverloaded的提交和PolicyDropOldest丢弃的任务，不包括工作池已释放、正在排空等原因导致的提交失败
+// 返回ErrPoolBusy后转交给溢出工作池的任务同样计入
+func (p *Pool) RejectedCount() uint64 {
+	if !p.isInitialized() {
+		return 0
+	}
+	return p.rejected.Load()
+}
+
+// Cap 返回工作池的容量
+func (p *Pool) Cap() int {
+	if !p.isInitialized() {
+		return 0
+	}
+	return int(p.capacity.Load())
+}
+
+// QueueLen 返回任务队列中排队等待的任务数量，不包括优先级任务，无缓冲的任务队列始终返回0
+// 设置了WithQueue时包括自定义任务队列中等待的任务
+func (p *Pool) QueueLen() int {
+	if !p.isInitialized() {
+		return 0
+	}
+
+	n := len(p.taskChan())
+	if p.custom != nil {
+		n += p.custom.len()
+	}
+	return n
+}
+
+// QueueHighWater 返回任务队列中排队任务数量曾经达到的最大值，不包括优先级任务，可通过ResetHighWater重新统计
+func (p *Pool) QueueHighWater() int {
+	if !p.isInitialized() {
+		return 0
+	}
+	return int(p.highWater.Load())
+}
+
+// ResetHighWater 清零任务队列排队数量的最大值，用于按统计窗口分别观察积压情况
+func (p *Pool) ResetHighWater() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.highWater.Store(0)
+}
+
+// updateHighWater 在任务被接收后更新任务队列排队数量的最大值
+func (p *Pool) updateHighWater() {
+	n := int64(p.QueueLen())
+	for {
+		old := p.highWater.Load()
+		if n <= old || p.highWater.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+// QueueCap 返回任务队列的缓冲区大小，即WithQueueSize设置的值，无缓冲的任务队列返回0
+func (p *Pool) QueueCap() int {
+	if !p.isInitialized() {
+		return 0
+	}
+	return cap(p.taskChan())
+}
+
+// ResizeQueue 在运行时将任务队列的缓冲区大小调整为n，已排队的任务按原顺序迁移到新的任务队列，不会丢失
+// 调整期间提交方会等待，已阻塞在提交中的调用方暂时让出，调整后向新的任务队列交付；n小于当前排队的任务数量时不做调整并返回ErrQueueTooSmall
+// n为负数或在PolicyDropOldest策略下为0时返回ErrInvalidOption，工作池已开始释放时返回ErrWorkerPoolFreed
+func (p *Pool) ResizeQueue(n int) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if n < 0 {
+		return fmt.Errorf("%w: negative queue size %d", ErrInvalidOption, n)
+	}
+	if n == 0 && p.queueFullPolicy == PolicyDropOldest {
+		return fmt.Errorf("%w: %s policy requires a buffered queue", ErrInvalidOption, p.queueFullPolicy)
+	}
+
+	// 持有写锁期间没有提交方在交付任务，worker只会减少排队的任务
+	p.lockAccept()
+	defer p.unlockAccept()
+
+	if p.IsFreed() {
+		return ErrWorkerPoolFreed
+	}
+
+	old := p.taskChan()
+	if n == cap(old) {
+		return nil
+	}
+	if backlog := len(old); backlog > n {
+		return fmt.Errorf("%w: %d tasks queued, new size %d", ErrQueueTooSmall, backlog, n)
+	}
+
+	tasks := make(chan Task, n)
+	p.tasks.Store(&tasks)
+migrate:
+	for {
+		select {
+		case t := <-old:
+			tasks <- t
+		default:
+			break migrate
+		}
+	}
+
+	// 关闭旧的通道，唤醒阻塞在旧通道上的worker重新读取任务队列
+	close(old)
+	p.notify()
+
+	p.logf("workerpool queue resize to %d\n", n)
+	return nil
+}
+
+// taskChan 返回当前的任务队列通道，工作池未初始化时返回nil
+func (p *Pool) taskChan() chan Task {
+	tasks := p.tasks.Load()
+	if tasks == nil {
+		return nil
+	}
+	return *tasks
+}
+
+// Tune 在运行时调整工作池容量
+// 扩容时run会继续创建worker直到达到新容量；缩容时多余的worker在完成当前任务后退出
+// 缩容不会中断或丢弃任何任务：空闲的worker被唤醒后立即退出，执行中的worker完成手头的任务后才退出，
+// 队列中的任务由剩余的worker继续执行；缩容后在多余的worker退出前再次扩容时，这些worker会被保留
+func (p *Pool) Tune(capacity int) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if capacity < 0 || capacity > maxCapacity {
+		return fmt.Errorf("%w: %d", ErrInvalidCapacity, capacity)
+	}
+
+	select {
+	case <-p.quit:
+		return ErrWorkerPoolFreed
+	default:
+	}
+
+	old := int(p.capacity.Swap(int64(capacity)))
+	p.notify()
+	if capacity >= old {
+		p.wakeAvailable()
+		return nil
+	}
+
+	// 唤醒空闲worker检查是否需要退出，正在执行任务的worker会在任务完成后自行检查
+	for i := capacity; i < old; i++ {
+		select {
+		case p.retire <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// addPending 在任务提交前增加未完成任务计数
+func (p *Pool) addPending() {
+	p.pendingMu.Lock()
+	p.pending++
+	p.pendingMu.Unlock()
+}
+
+// donePending 在提交失败或任务被丢弃后减少未完成任务计数，计数归零时唤醒所有WaitIdle调用方
+// 只有上次空闲之后有任务执行完成时才通知空闲回调，避免空闲的工作池上每次被拒绝的提交都触发回调
+func (p *Pool) donePending() {
+	p.releasePending(false)
+}
+
+// completePending 在任务执行完成后减少未完成任务计数，计数归零时同时通知空闲回调
+func (p *Pool) completePending() {
+	p.releasePending(true)
+}
+
+// releasePending 减少未完成任务计数，completed表示有任务执行完成
+func (p *Pool) releasePending(completed bool) {
+	p.pendingMu.Lock()
+	p.pending--
+	if completed {
+		p.idleArmed = true
+	}
+	if p.pending == 0 {
+		if p.idleArmed {
+			p.idleArmed = false
+			p.signalIdle()
+		}
+		if p.idle != nil {
+			close(p.idle)
+			p.idle = nil
+		}
+	}
+	p.pendingMu.Unlock()
+}
+
+// WaitIdle 阻塞等待所有已提交的任务（包括排队中和执行中的任务）执行完成，工作池在返回后仍可继续使用
+// 工作池被释放时WaitIdle也会返回
+func (p *Pool) WaitIdle() {
+	_ = p.WaitIdleContext(context.Background())
+}
+
+// WaitIdleContext 与WaitIdle相同，ctx结束时返回ctx.Err()，工作池被释放时返回ErrWorkerPoolFreed
+func (p *Pool) WaitIdleContext(ctx context.Context) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	p.pendingMu.Lock()
+	if p.pending == 0 {
+		p.pendingMu.Unlock()
+		return nil
+	}
+	if p.idle == nil {
+		p.idle = make(chan struct{})
+	}
+	idle := p.idle
+	p.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.quit:
+		return ErrWorkerPoolFreed
+	}
+}
+
+// doneRunning 在任务执行结束后减少执行中的任务计数，有WaitAvailable调用方或加权任务等待时唤醒它们
+func (p *Pool) doneRunning(id int) {
+	p.checkRelieved(id, p.running.Add(-1))
+	if p.availWaiters.Load() > 0 || p.extraWeight.Load() > 0 {
+		p.wakeAvailable()
+	}
+}
+
+// wakeAvailable 唤醒所有等待空闲容量的WaitAvailable调用方，由调用方重新检查是否有空闲容量
+func (p *Pool) wakeAvailable() {
+	p.availMu.Lock()
+	if p.avail != nil {
+		close(p.avail)
+		p.avail = nil
+	}
+	p.availMu.Unlock()
+}
+
+// WaitAvailable 阻塞等待直到工作池至少有一个空闲的worker，即Available大于0
+// ctx结束时返回ctx.Err()，工作池被释放时返回ErrWorkerPoolFreed
+// 返回nil只表示返回时有空闲容量，并发的提交方仍可能抢先占用
+func (p *Pool) WaitAvailable(ctx context.Context) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	p.availWaiters.Add(1)
+	defer p.availWaiters.Add(-1)
+
+	for {
+		select {
+		case <-p.quit:
+			return ErrWorkerPoolFreed
+		default:
+		}
+
+		p.availMu.Lock()
+		if p.Available() > 0 {
+			p.availMu.Unlock()
+			return nil
+		}
+		if p.avail == nil {
+			p.avail = make(chan struct{})
+		}
+		avail := p.avail
+		p.availMu.Unlock()
+
+		select {
+		case <-avail:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.quit:
+			return ErrWorkerPoolFreed
+		}
+	}
+}
+
+// WaitRunning 阻塞等待直到正在执行任务的worker数量不少于n，即Running大于等于n
+// ctx结束时返回ctx.Err()，工作池被释放时返回ErrWorkerPoolFreed；适用于测试和编排中等待工作池进入预期的负载
+func (p *Pool) WaitRunning(n int, ctx context.Context) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	p.runningWaiters.Add(1)
+	defer p.runningWaiters.Add(-1)
+
+	for {
+		p.runningMu.Lock()
+		if p.Running() >= n {
+			p.runningMu.Unlock()
+			return nil
+		}
+		if p.runningCh == nil {
+			p.runningCh = make(chan struct{})
+		}
+		ch := p.runningCh
+		p.runningMu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.quit:
+			return ErrWorkerPoolFreed
+		}
+	}
+}
+
+// notifyRunning 在任务开始执行后调用，有WaitRunning调用方等待时唤醒它们重新检查
+func (p *Pool) notifyRunning() {
+	if p.runningWaiters.Load() == 0 {
+		return
+	}
+
+	p.runningMu.Lock()
+	if p.runningCh != nil {
+		close(p.runningCh)
+		p.runningCh = nil
+	}
+	p.runningMu.Unlock()
+}
+
+// enterSchedule 在提交方交付任务前调用，检查工作池是否接收新任务并计入未完成任务
+// 返回nil时调用方必须在交付结束后调用exitSchedule，交付失败时还需要自行调用donePending
+// 先计入未完成任务再检查，保证Drain要么拒绝该任务，要么等待它执行完成
+func (p *Pool) enterSchedule() error {
+	// Reset期间直接返回，不等待重新初始化完成
+	if p.isInitialized() && p.State() == StateResetting {
+		return ErrPoolResetting
+	}
+
+	// 持有读锁后再计入，避免计数被并发的Reset清零
+	p.acceptMu.RLock()
+	p.addPending()
+	if err := p.checkAccepting(); err != nil {
+		p.acceptMu.RUnlock()
+		p.donePending()
+		return err
+	}
+	return nil
+}
+
+// exitSchedule 在提交方交付任务结束后调用，与enterSchedule配对
+func (p *Pool) exitSchedule() {
+	p.acceptMu.RUnlock()
+}
+
+// acceptYield 表示一轮让出acceptMu读锁的过程，ResizeQueue、Barrier获取写锁前关闭preempt，释放写锁后关闭done
+// Go的RWMutex在有调用方等待写锁时会阻塞新的读锁，阻塞交付中的提交方若继续持有读锁，
+// 在worker中通过ScheduleNested等方法提交的任务就无法获取读锁，worker也就无法腾出提交方等待的位置
+type acceptYield struct {
+	writers int           // 等待或持有写锁的调用方数量，由yieldMu保护
+	preempt chan struct{} // 有调用方等待写锁时关闭，通知阻塞交付中的提交方让出读锁
+	done    chan struct{} // 本轮全部调用方释放写锁后关闭，让出读锁的提交方此后重新获取
+}
+
+// acceptYield 返回当前一轮让出读锁的通知，阻塞交付的提交方在持有读锁期间调用并等待其preempt
+func (p *Pool) acceptYield() *acceptYield {
+	p.yieldMu.Lock()
+	defer p.yieldMu.Unlock()
+
+	if p.yield == nil {
+		p.yield = &acceptYield{preempt: make(chan struct{}), done: make(chan struct{})}
+	}
+	return p.yield
+}
+
+// yieldAccept 在y的preempt关闭后让出读锁，等待本轮写锁全部释放后重新获取，调用方需持有acceptMu的读锁
+// quit为调用方获取读锁时的quit，返回false表示让出期间工作池被释放并通过Reset重新初始化，调用方计入的未完成任务已被清零
+func (p *Pool) yieldAccept(y *acceptYield, quit chan struct{}) bool {
+	p.acceptMu.RUnlock()
+	<-y.done
+	p.acceptMu.RLock()
+	return p.quit == quit
+}
+
+// lockAccept 通知阻塞交付中的提交方让出读锁，然后获取acceptMu的写锁，与unlockAccept配对
+// 需要替换任务队列或修改屏障的调用方使用它，避免等待写锁期间阻塞worker中的提交
+func (p *Pool) lockAccept() {
+	p.yieldMu.Lock()
+	if p.yield == nil {
+		p.yield = &acceptYield{preempt: make(chan struct{}), done: make(chan struct{})}
+	}
+	y := p.yield
+	if y.writers == 0 {
+		close(y.preempt)
+	}
+	y.writers++
+	p.yieldMu.Unlock()
+
+	p.acceptMu.Lock()
+}
+
+// unlockAccept 释放lockAccept获取的写锁，本轮最后一个调用方释放时唤醒让出读锁的提交方
+func (p *Pool) unlockAccept() {
+	p.acceptMu.Unlock()
+
+	p.yieldMu.Lock()
+	y := p.yield
+	y.writers--
+	if y.writers == 0 {
+		close(y.done)
+		p.yield = nil
+	}
+	p.yieldMu.Unlock()
+}
+
+// checkAccepting 检查工作池当前是否接收新任务
+// 工作池未通过New等构造函数创建时返回ErrPoolNotInitialized，Free/Shutdown开始后返回ErrWorkerPoolFreed，正在排空时返回ErrPoolDraining
+// 正在通过Reset重新初始化时返回ErrPoolResetting
+// 以原子的state作为是否接收任务的标记，而不是依赖select在quit和任务通道之间的随机选择
+func (p *Pool) checkAccepting() error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.State() == StateResetting {
+		return ErrPoolResetting
+	}
+	if p.IsFreed() {
+		return ErrWorkerPoolFreed
+	}
+
+	if p.draining.Load() {
+		return ErrPoolDraining
+	}
+	return nil
+}
+
+// isInitialized 返回工作池是否通过New等构造函数创建，p为nil时返回false
+// 导出的方法首先调用它，未初始化时返回ErrPoolNotInitialized或零值，不会访问未初始化的字段
+func (p *Pool) isInitialized() bool {
+	return p != nil && p.initialized
+}
+
+// Drain 暂停接收新任务，并阻塞等待队列中和执行中的任务全部完成
+// 排空期间Schedule等提交方法返回ErrPoolDraining，调用Resume后恢复接收；工作池被释放时Drain也会返回
+// 通过ScheduleAfter提交且尚未到期的延迟任务不在等待范围内，它们在排空期间到期时会被丢弃
+func (p *Pool) Drain() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.draining.Store(true)
+	p.WaitIdle()
+}
+
+// Resume 恢复接收新任务，与Drain配合使用，工作池未在排空时调用没有效果
+func (p *Pool) Resume() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.draining.Store(false)
 }
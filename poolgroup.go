@@ -0,0 +1,47 @@
+package main
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// PoolGroup 表示一组按key分片的工作池，同一个key的任务总是交给同一个工作池执行
+// 适用于每个分片（例如数据库分片）使用独立工作池的场景，成员工作池之间互不影响
+type PoolGroup struct {
+	pools []*Pool
+}
+
+// NewPoolGroup 创建由pools组成的工作池组，成员的顺序决定key到工作池的映射，创建后不应再改变
+func NewPoolGroup(pools ...*Pool) *PoolGroup {
+	return &PoolGroup{pools: append([]*Pool(nil), pools...)}
+}
+
+// Pool 返回key对应的成员工作池，工作池组为空时返回nil
+func (g *PoolGroup) Pool(key string) *Pool {
+	if len(g.pools) == 0 {
+		return nil
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return g.pools[h.Sum32()%uint32(len(g.pools))]
+}
+
+// Schedule 将任务提交到key对应的成员工作池，阻塞和错误的语义与该工作池的Schedule相同
+// 工作池组为空时返回ErrPoolNotInitialized
+func (g *PoolGroup) Schedule(key string, t Task) error {
+	return g.Pool(key).Schedule(t)
+}
+
+// FreeAll 并发释放所有成员工作池，等待全部释放完成后返回
+func (g *PoolGroup) FreeAll() {
+	var wg sync.WaitGroup
+	for _, p := range g.pools {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Free()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// priorityItem 定义优先级队列中的元素
+type priorityItem struct {
+	task     Task   // 待执行的任务
+	priority int    // 任务优先级，数值越大越先执行
+	seq      uint64 // 提交序号，优先级相同时先提交的先执行
+}
+
+// priorityItemPool 复用priorityItem，减少高频提交优先级任务时的内存分配
+var priorityItemPool = sync.Pool{
+	New: func() any { return new(priorityItem) },
+}
+
+// release 清空元素内容后放回priorityItemPool，避免任务之间残留状态
+func (item *priorityItem) release() {
+	*item = priorityItem{}
+	priorityItemPool.Put(item)
+}
+
+// priorityQueue 基于堆实现的优先级队列，实现了heap.Interface
+type priorityQueue []*priorityItem
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x any) { *q = append(*q, x.(*priorityItem)) }
+
+func (q *priorityQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// SchedulePriority 按优先级提交一个任务到工作池执行，priority数值越大越先执行
+// 优先级任务进入内部的堆队列，由分发协程按优先级从高到低交给worker，不会阻塞提交方
+// 通过Schedule提交的普通任务优先级最低，只有在没有等待中的优先级任务时才会被worker处理
+func (p *Pool) SchedulePriority(t Task, priority int) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.synchronous {
+		return p.runInline(t)
+	}
+
+	if err := p.enterSchedule(); err != nil {
+		return err
+	}
+	defer p.exitSchedule()
+
+	item := priorityItemPool.Get().(*priorityItem)
+	item.task = t
+	item.priority = priority
+
+	p.prioMu.Lock()
+	p.prioSeq++
+	item.seq = p.prioSeq
+	if p.fair != nil {
+		p.fair.push(item)
+	} else {
+		heap.Push(&p.prioQueue, item)
+	}
+	p.prioMu.Unlock()
+
+	p.accepted()
+	p.signalPriority()
+	return nil
+}
+
+// signalPriority 非阻塞地通知分发协程有新的优先级任务
+func (p *Pool) signalPriority() {
+	select {
+	case p.prioSignal <- struct{}{}:
+	default:
+	}
+}
+
+// popPriority 取出优先级最高的任务，配置了WithFairness时按权重轮转取出
+func (p *Pool) popPriority() (*priorityItem, bool) {
+	p.prioMu.Lock()
+	defer p.prioMu.Unlock()
+
+	if p.fair != nil {
+		return p.fair.pop()
+	}
+	if p.prioQueue.Len() == 0 {
+		return nil, false
+	}
+	return heap.Pop(&p.prioQueue).(*priorityItem), true
+}
+
+// pushPriority 将取出但未能分发的任务放回优先级队列
+func (p *Pool) pushPriority(item *priorityItem) {
+	p.prioMu.Lock()
+	if p.fair != nil {
+		p.fair.unpop(item)
+	} else {
+		heap.Push(&p.prioQueue, item)
+	}
+	p.prioMu.Unlock()
+}
+
+// prioLen 返回等待分发的优先级任务数量，调用方需持有prioMu
+func (p *Pool) prioLen() int {
+	if p.fair != nil {
+		return p.fair.len()
+	}
+	return p.prioQueue.Len()
+}
+
+// dispatch 分发协程主循环，按优先级从高到低将任务交给worker
+func (p *Pool) dispatch() {
+	defer p.wg.Done()
+
+	for {
+		item, ok := p.popPriority()
+		if !ok {
+			select {
+			case <-p.quit:
+				return
+			case <-p.prioSignal:
+			}
+			continue
+		}
+
+		select {
+		case <-p.quit:
+			p.pushPriority(item)
+			return
+		case <-p.prioSignal:
+			// 等待worker期间有新的任务提交，放回后重新取出优先级最高的任务
+			p.pushPriority(item)
+		case p.urgent <- item.task:
+			item.release()
+		case p.spawn <- item.task:
+			item.release()
+		}
+	}
+}
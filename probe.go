@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// startupProbeTimeout 启动探测等待探测任务被执行的最长时间
+const startupProbeTimeout = time.Second
+
+// WithStartupProbe 设置NewWithError在返回前探测工作池能否执行任务的选项，默认不探测
+// 开启后NewWithError提交一个内部的空任务，并等待worker执行它，超过startupProbeTimeout仍未执行时释放工作池并返回ErrStartupProbe
+// 探测任务同样计入提交和完成的任务数量；同步模式下没有worker，不进行探测；New不受该选项影响
+func WithStartupProbe(b bool) Option {
+	return func(p *Pool) {
+		p.startupProbe = b
+	}
+}
+
+// probe 提交探测任务并等待它被worker执行
+func (p *Pool) probe() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timer := p.clock.AfterFunc(startupProbeTimeout, cancel)
+	defer timer.Stop()
+
+	done := make(chan struct{})
+	if _, err := p.scheduleWait(ctx, func() { close(done) }); err != nil {
+		return fmt.Errorf("%w: %v", ErrStartupProbe, err)
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: probe task not run within %v", ErrStartupProbe, startupProbeTimeout)
+	}
+}
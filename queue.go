@@ -0,0 +1,97 @@
+package main
+
+import "sync"
+
+// TaskQueue 定义可替换的任务队列，用于实现持久化、自定义顺序等排队方式
+// 工作池通过内部的互斥锁串行调用这些方法，实现无需自行保证并发安全；Push不应阻塞，队列需要自行决定容量
+type TaskQueue interface {
+	Push(t Task)       // 放入一个等待执行的任务
+	Pop() (Task, bool) // 取出下一个要执行的任务，队列为空时返回false
+	Len() int          // 返回队列中等待的任务数量
+}
+
+// WithQueue 设置使用自定义任务队列的选项，默认使用内置的基于通道的任务队列
+// 设置后普通任务在提交时放入q，空闲的worker按q的Pop顺序取出执行；提交不会阻塞，非阻塞模式、队列已满策略、
+// 阻塞调用方数量上限以及WithQueueSize、ResizeQueue对q不生效，与WithQueueSize同时设置时NewWithError返回ErrInvalidOption
+// 优先级任务仍进入内部的优先级队列，先于q中的任务执行；优雅关闭时q中剩余的任务同样会被执行，Reset时被丢弃
+// q的取出顺序不确定，依赖先进先出的Flush和Barrier返回ErrUnorderedQueue
+func WithQueue(q TaskQueue) Option {
+	return func(p *Pool) {
+		p.queue = q
+	}
+}
+
+// customQueue 在内置任务队列之外包装自定义任务队列，串行化对它的访问并通知空闲的worker
+type customQueue struct {
+	mu    sync.Mutex
+	q     TaskQueue
+	ready chan struct{} // 队列中有任务时通知一个空闲的worker，取出任务的worker在队列仍非空时继续通知下一个
+}
+
+// newCustomQueue 包装自定义任务队列
+func newCustomQueue(q TaskQueue) *customQueue {
+	return &customQueue{q: q, ready: make(chan struct{}, 1)}
+}
+
+// push 放入一个任务并通知空闲的worker
+func (c *customQueue) push(t Task) {
+	c.mu.Lock()
+	c.q.Push(t)
+	c.mu.Unlock()
+
+	c.signal()
+}
+
+// pop 取出下一个任务，队列仍非空时通知下一个空闲的worker
+func (c *customQueue) pop() (Task, bool) {
+	c.mu.Lock()
+	t, ok := c.q.Pop()
+	more := c.q.Len() > 0
+	c.mu.Unlock()
+
+	if more {
+		c.signal()
+	}
+	return t, ok
+}
+
+// len 返回队列中等待的任务数量
+func (c *customQueue) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.q.Len()
+}
+
+// signal 非阻塞地通知空闲的worker
+func (c *customQueue) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// queueReady 返回自定义任务队列有任务时的通知通道，未设置WithQueue时返回nil
+func (p *Pool) queueReady() chan struct{} {
+	if p.custom == nil {
+		return nil
+	}
+	return p.custom.ready
+}
+
+// popQueue 从自定义任务队列取出一个任务，未设置WithQueue或队列为空时返回nil
+func (p *Pool) popQueue() Task {
+	if p.custom == nil {
+		return nil
+	}
+	if t, ok := p.custom.pop(); ok {
+		return t
+	}
+	return nil
+}
+
+// pushQueue 将已通过enterSchedule检查的任务放入自定义任务队列
+func (p *Pool) pushQueue(t Task) {
+	p.custom.push(t)
+	p.accepted()
+}
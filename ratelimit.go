@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter 容量为1的令牌桶，按固定间隔发放令牌
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // 发放令牌的间隔
+	next     time.Time     // 下一个令牌可用的时间
+}
+
+// newRateLimiter 创建每秒发放perSecond个令牌的限流器
+func newRateLimiter(perSecond int) *rateLimiter {
+	return &rateLimiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+// reserve 在now时刻预定一个令牌，返回需要等待的时间
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+
+	return d
+}
+
+// WithRateLimit 设置任务分发速率的选项，每秒最多有perSecond个任务开始执行
+// 超过速率的任务会等待令牌而不是被拒绝，perSecond不大于0时不限速
+func WithRateLimit(perSecond int) Option {
+	return func(p *Pool) {
+		if perSecond > 0 {
+			p.limiter = newRateLimiter(perSecond)
+		}
+	}
+}
+
+// throttle 在任务执行前等待限流令牌，工作池被释放时不再等待
+func (p *Pool) throttle() {
+	if p.limiter == nil {
+		return
+	}
+
+	d := p.limiter.reserve(p.clock.Now())
+	if d <= 0 {
+		return
+	}
+
+	timer := p.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-p.quit:
+	}
+}
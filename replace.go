@@ -0,0 +1,61 @@
+package main
+
+import "sync/atomic"
+
+// replaceEntry 记录通过ScheduleReplace提交的一个任务的状态
+type replaceEntry struct {
+	state atomic.Int32 // taskWaiting、taskStarted或taskCanceled
+}
+
+// ScheduleReplace 提交一个带key的任务到工作池执行，同一key之前提交的任务仍在排队时被新任务取代，不再执行
+// 之前的任务已经开始执行时不受影响，新任务照常排队；适用于只需要处理最新一次提交的场景，例如输入变化后重新渲染
+// 被取代的任务仍会占用队列中的位置，直到worker取出它后直接跳过；提交失败时之前的任务不会被取代
+func (p *Pool) ScheduleReplace(key string, t Task) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+
+	e := new(replaceEntry)
+
+	p.replaceMu.Lock()
+	if p.replacing == nil {
+		p.replacing = make(map[string]*replaceEntry)
+	}
+	old := p.replacing[key]
+	p.replacing[key] = e
+	p.replaceMu.Unlock()
+
+	err := p.Schedule(func() {
+		if !e.state.CompareAndSwap(taskWaiting, taskStarted) {
+			return
+		}
+		p.doneReplace(key, e)
+		t()
+	})
+	if err != nil {
+		p.replaceMu.Lock()
+		if p.replacing[key] == e {
+			if old != nil && old.state.Load() == taskWaiting {
+				p.replacing[key] = old
+			} else {
+				delete(p.replacing, key)
+			}
+		}
+		p.replaceMu.Unlock()
+		return err
+	}
+
+	if old != nil {
+		old.state.CompareAndSwap(taskWaiting, taskCanceled)
+	}
+	return nil
+}
+
+// doneReplace 在任务开始执行时移除其key，此后同一key的新任务不会取代它
+func (p *Pool) doneReplace(key string, e *replaceEntry) {
+	p.replaceMu.Lock()
+	if p.replacing[key] == e {
+		delete(p.replacing, key)
+	}
+	p.replaceMu.Unlock()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ScheduleWithRetry 提交一个可重试的任务到工作池执行，任务panic时由同一个worker重新执行，最多执行attempts次
+// 每次panic都会调用panic处理函数，最后一次执行仍然panic时与普通任务的panic处理方式相同
+// 重试在worker上进行，不会阻塞提交方；attempts不大于1时与Schedule相同，只适合用于幂等的任务
+func (p *Pool) ScheduleWithRetry(t Task, attempts int) error {
+	if attempts <= 1 {
+		return p.Schedule(t)
+	}
+
+	return p.Schedule(func() {
+		for i := 1; i < attempts; i++ {
+			if p.tryRun(t) {
+				return
+			}
+			p.debugf("retry task, attempt %d/%d\n", i+1, attempts)
+		}
+		t()
+	})
+}
+
+// tryRun 执行t并捕获panic，发生panic时交给panic处理函数并返回false，关闭捕获时panic继续向上传播
+func (p *Pool) tryRun(t Task) (ok bool) {
+	defer func() {
+		if p.noRecover {
+			return
+		}
+		if err := recover(); err != nil {
+			p.panics.Add(1)
+			p.handlePanic(0, err, debug.Stack())
+			p.checkRepanic(err)
+		}
+	}()
+
+	t()
+	return true
+}
+
+// BackoffPolicy 定义ScheduleRetry按返回错误重试时的指数退避策略
+type BackoffPolicy struct {
+	Base       time.Duration   // 第一次重试前的等待时间，之后每次重试翻倍
+	Max        time.Duration   // 单次等待时间的上限，不大于0时不限制
+	Attempts   int             // 最多执行的次数，不大于1时只执行一次，不重试
+	OnComplete func(err error) // 最终执行成功或重试次数耗尽后调用，参数为最后一次执行返回的错误，可以为nil
+}
+
+// delay 返回第n次重试前的等待时间，n从1开始
+func (b BackoffPolicy) delay(n int) time.Duration {
+	d := b.Base
+	for i := 1; i < n && d > 0; i++ {
+		if b.Max > 0 && d >= b.Max {
+			break
+		}
+		d *= 2
+	}
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// ScheduleRetry 提交一个按返回错误重试的任务到工作池执行，fn返回错误时按policy指数退避后重新执行，直到返回nil或执行次数耗尽
+// 退避等待通过工作池的时钟在后台计时，期间不占用worker，到期后重新提交；fn的panic被转换为包装了ErrTaskPanic的错误并同样重试
+// 最终结果通过policy.OnComplete通知；重新提交失败时OnComplete收到同时包装了提交错误和最后一次执行错误的错误
+// 工作池在退避等待期间被释放时剩余的重试不再执行，OnComplete也不会被调用；等待中的重试不计入WaitIdle等待的任务
+func (p *Pool) ScheduleRetry(fn func() error, policy BackoffPolicy) error {
+	return p.Schedule(p.retryAttempt(fn, policy, 1))
+}
+
+// retryAttempt 返回执行第n次尝试的任务
+func (p *Pool) retryAttempt(fn func() error, policy BackoffPolicy, n int) Task {
+	return func() {
+		err := runReturning(fn)
+		if err == nil || n >= policy.Attempts {
+			policy.complete(err)
+			return
+		}
+
+		d := policy.delay(n)
+		p.debugf("retry task after %v, attempt %d/%d failed: %v\n", d, n, policy.Attempts, err)
+		if serr := p.ScheduleAfter(p.retryAttempt(fn, policy, n+1), d); serr != nil {
+			policy.complete(errors.Join(serr, err))
+		}
+	}
+}
+
+// complete 调用完成回调
+func (b BackoffPolicy) complete(err error) {
+	if b.OnComplete != nil {
+		b.OnComplete(err)
+	}
+}
+
+// runReturning 执行fn，fn panic时返回包装了ErrTaskPanic的错误
+func runReturning(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrTaskPanic, r)
+		}
+	}()
+
+	return fn()
+}
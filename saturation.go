@@ -0,0 +1,33 @@
+package main
+
+// WithSaturationCallback 设置工作池饱和与恢复时回调的选项
+// 正在执行任务的worker数量达到容量时调用onSaturated，此后降到容量以下时调用onRelieved
+// 两个回调交替触发，饱和期间反复有任务完成和开始不会重复回调；回调在worker协程中同步执行，应当尽快返回
+func WithSaturationCallback(onSaturated, onRelieved func()) Option {
+	return func(p *Pool) {
+		p.onSaturated = onSaturated
+		p.onRelieved = onRelieved
+	}
+}
+
+// checkSaturated 在任务开始执行后调用，running为开始执行后正在执行任务的数量
+func (p *Pool) checkSaturated(id int, running int64) {
+	if p.onSaturated == nil && p.onRelieved == nil {
+		return
+	}
+
+	if running >= p.capacity.Load() && p.saturated.CompareAndSwap(false, true) && p.onSaturated != nil {
+		p.callHook(id, p.onSaturated)
+	}
+}
+
+// checkRelieved 在任务执行结束后调用，running为结束后正在执行任务的数量
+func (p *Pool) checkRelieved(id int, running int64) {
+	if p.onSaturated == nil && p.onRelieved == nil {
+		return
+	}
+
+	if running < p.capacity.Load() && p.saturated.CompareAndSwap(true, false) && p.onRelieved != nil {
+		p.callHook(id, p.onRelieved)
+	}
+}
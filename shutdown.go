@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ShutdownPhase 表示GracefulStop所处的关闭阶段
+type ShutdownPhase int
+
+const (
+	PhaseStopAccepting ShutdownPhase = iota // 停止接收新任务
+	PhaseDrain                              // 等待队列中的任务全部开始执行
+	PhaseWaitInFlight                       // 等待执行中的任务全部完成
+	PhaseStopWorkers                        // 通知worker退出并等待它们退出
+)
+
+// String 返回关闭阶段的可读名称
+func (s ShutdownPhase) String() string {
+	switch s {
+	case PhaseStopAccepting:
+		return "stop-accepting"
+	case PhaseDrain:
+		return "drain"
+	case PhaseWaitInFlight:
+		return "wait-in-flight"
+	case PhaseStopWorkers:
+		return "stop-workers"
+	default:
+		return "unknown"
+	}
+}
+
+// ShutdownError 表示GracefulStop在某个阶段超时的错误，可以通过errors.Is匹配ErrShutdownTimeout和ctx的错误
+type ShutdownError struct {
+	Phase ShutdownPhase // 超时的阶段
+	Err   error         // ctx结束的原因
+	Stuck []string      // 超时时仍在执行的、通过ScheduleLabeled提交的任务的标签，按字典序排列
+}
+
+// Error 返回错误描述，有仍在执行的带标签任务时列出它们的标签
+func (e *ShutdownError) Error() string {
+	msg := fmt.Sprintf("workerpool shutdown timeout in %s phase: %v", e.Phase, e.Err)
+	if len(e.Stuck) > 0 {
+		msg += fmt.Sprintf(" (stuck tasks: %s)", strings.Join(e.Stuck, ", "))
+	}
+	return msg
+}
+
+// Unwrap 返回ErrShutdownTimeout和ctx结束的原因
+func (e *ShutdownError) Unwrap() []error {
+	return []error{ErrShutdownTimeout, e.Err}
+}
+
+// GracefulStop 按阶段优雅关闭工作池：停止接收新任务，等待队列排空，等待执行中的任务完成，最后通知worker退出
+// 每个阶段都受ctx约束，ctx结束时返回*ShutdownError，其中记录了超时的阶段以及仍在执行的带标签任务
+// 在前三个阶段超时时工作池保持排空状态，不再接收新任务，可以调用Resume恢复或Free释放；在最后一个阶段超时时关闭流程会在后台继续进行
+// 工作池未通过New等构造函数创建时返回ErrPoolNotInitialized，关闭前已被释放时返回ErrWorkerPoolFreed
+func (p *Pool) GracefulStop(ctx context.Context) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.State() != StateRunning {
+		return ErrWorkerPoolFreed
+	}
+
+	// 停止接收新任务后，已通过检查的提交方仍计入未完成任务，由后续阶段等待
+	if err := ctx.Err(); err != nil {
+		return p.shutdownError(PhaseStopAccepting, err)
+	}
+	p.draining.Store(true)
+	p.logf("workerpool stop accepting\n")
+
+	if err := p.waitDrained(ctx); err != nil {
+		return p.shutdownError(PhaseDrain, err)
+	}
+	if err := p.WaitIdleContext(ctx); err != nil {
+		return p.shutdownError(PhaseWaitInFlight, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return p.shutdownError(PhaseStopWorkers, ctx.Err())
+	}
+}
+
+// shutdownError 将阶段等待返回的错误转换为GracefulStop的返回值，并记录此时仍在执行的带标签任务
+// 等待期间工作池被其他调用方释放时直接返回该错误
+func (p *Pool) shutdownError(phase ShutdownPhase, err error) error {
+	if errors.Is(err, ErrWorkerPoolFreed) {
+		return err
+	}
+	return &ShutdownError{Phase: phase, Err: err, Stuck: p.runningLabels()}
+}
+
+// waitDrained 阻塞等待已提交的任务全部开始执行，即队列中和阻塞在提交中的任务数量降为0
+// 每当有任务开始执行或未完成任务全部结束时重新检查
+func (p *Pool) waitDrained(ctx context.Context) error {
+	p.runningWaiters.Add(1)
+	defer p.runningWaiters.Add(-1)
+
+	for {
+		p.runningMu.Lock()
+		if p.waiting() <= 0 {
+			p.runningMu.Unlock()
+			return nil
+		}
+		if p.runningCh == nil {
+			p.runningCh = make(chan struct{})
+		}
+		ch := p.runningCh
+		p.runningMu.Unlock()
+
+		p.pendingMu.Lock()
+		if p.pending == 0 {
+			p.pendingMu.Unlock()
+			return nil
+		}
+		if p.idle == nil {
+			p.idle = make(chan struct{})
+		}
+		idle := p.idle
+		p.pendingMu.Unlock()
+
+		select {
+		case <-ch:
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.quit:
+			return ErrWorkerPoolFreed
+		}
+	}
+}
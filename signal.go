@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WithSignalShutdown 设置收到指定信号时优雅关闭工作池的选项，未指定信号时默认监听SIGINT和SIGTERM
+// 信号处理在工作池释放时移除
+func WithSignalShutdown(signals ...os.Signal) Option {
+	return func(p *Pool) {
+		if len(signals) == 0 {
+			signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+		}
+		p.signals = signals
+	}
+}
+
+// watchSignals 安装信号处理，收到信号时优雅关闭工作池
+func (p *Pool) watchSignals() {
+	if len(p.signals) == 0 {
+		return
+	}
+
+	p.sigCh = make(chan os.Signal, 1)
+	signal.Notify(p.sigCh, p.signals...)
+
+	sigCh, quit := p.sigCh, p.quit
+	go func() {
+		select {
+		case sig := <-sigCh:
+			p.logf("receive signal[%v], shutdown\n", sig)
+			p.Shutdown()
+		case <-quit:
+		}
+	}()
+}
+
+// stopSignals 移除信号处理
+func (p *Pool) stopSignals() {
+	if p.sigCh != nil {
+		signal.Stop(p.sigCh)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "fmt"
+
+// WithMaxInFlightBytes 设置正在执行的任务总大小上限的选项，默认不限制
+// 只对通过ScheduleSized提交的任务生效，用于任务携带较大数据时限制它们占用的总内存，而不仅仅是任务数量；limit不大于0时不限制
+func WithMaxInFlightBytes(limit int64) Option {
+	return func(p *Pool) {
+		p.maxBytes = limit
+	}
+}
+
+// ScheduleSized 提交一个大小约为bytes的任务，阻塞和错误的语义与Schedule相同
+// 配置了WithMaxInFlightBytes时，任务在worker上开始执行前检查总大小，加上bytes超过上限则占用该worker等待，直到有任务执行结束释放出足够的大小
+// 任务从开始执行起计入总大小，执行结束（包括panic）后扣除，因此大任务之间会串行执行，小任务仍可并发；排队期间被丢弃的任务从未计入，不影响总大小
+// bytes小于0时按0处理，超过上限时返回ErrTaskTooLarge；等待期间不保证先开始等待的任务先执行，大任务可能被持续到来的小任务推迟
+// 工作池在等待期间被释放时任务不再执行；通过ScheduleSized提交的任务中阻塞等待另一个ScheduleSized任务结束可能因总大小无法释放而一直阻塞
+func (p *Pool) ScheduleSized(t Task, bytes int64) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if p.maxBytes <= 0 {
+		return p.Schedule(t)
+	}
+
+	bytes = max(bytes, 0)
+	if bytes > p.maxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds limit %d", ErrTaskTooLarge, bytes, p.maxBytes)
+	}
+
+	return p.Schedule(func() {
+		if err := p.acquireBytes(bytes); err != nil {
+			p.logf("sized task dropped: %v\n", err)
+			return
+		}
+		defer p.releaseBytes(bytes)
+		t()
+	})
+}
+
+// InFlightBytes 返回通过ScheduleSized提交、已开始且尚未执行结束的任务的总大小
+func (p *Pool) InFlightBytes() int64 {
+	if !p.isInitialized() {
+		return 0
+	}
+	return p.inFlightBytes.Load()
+}
+
+// acquireBytes 在worker上阻塞等待总大小加上bytes不超过上限后计入，工作池在等待期间被释放时返回ErrWorkerPoolFreed
+// 优雅关闭时worker会执行完剩余任务，执行中的任务结束后总大小总能释放，因此继续等待；关闭可能在等待期间才开始，quit关闭后再判断
+func (p *Pool) acquireBytes(bytes int64) error {
+	quit := p.quit
+	for {
+		p.bytesMu.Lock()
+		if p.inFlightBytes.Load()+bytes <= p.maxBytes {
+			p.inFlightBytes.Add(bytes)
+			p.bytesMu.Unlock()
+			return nil
+		}
+		if p.bytesFreed == nil {
+			p.bytesFreed = make(chan struct{})
+		}
+		freed := p.bytesFreed
+		p.bytesMu.Unlock()
+
+		select {
+		case <-freed:
+		case <-quit:
+			// graceful在quit关闭前设置
+			if !p.graceful.Load() {
+				return ErrWorkerPoolFreed
+			}
+			quit = nil
+		}
+	}
+}
+
+// releaseBytes 扣除任务的大小，并唤醒所有等待的任务重新检查
+func (p *Pool) releaseBytes(bytes int64) {
+	p.bytesMu.Lock()
+	p.inFlightBytes.Add(-bytes)
+	if p.bytesFreed != nil {
+		close(p.bytesFreed)
+		p.bytesFreed = nil
+	}
+	p.bytesMu.Unlock()
+}
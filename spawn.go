@@ -0,0 +1,57 @@
+package main
+
+// SpawnStrategy 定义run创建worker的策略
+type SpawnStrategy int
+
+const (
+	SpawnLazy  SpawnStrategy = iota // 只在有任务等待且没有空闲worker时创建worker，默认策略
+	SpawnEager                      // 主动创建worker直到达到容量，任务到来时无需等待worker创建
+)
+
+// String 返回策略的可读名称
+func (s SpawnStrategy) String() string {
+	switch s {
+	case SpawnLazy:
+		return "lazy"
+	case SpawnEager:
+		return "eager"
+	default:
+		return "unknown"
+	}
+}
+
+// WithSpawnStrategy 设置创建worker策略的选项，默认值为：SpawnLazy
+// SpawnEager适合对延迟敏感的场景，缩容后扩容或worker因WithWorkerMaxTasks退出时也会立即补足，空闲超时不生效
+// SpawnLazy只按负载创建worker，适合对资源敏感的场景；非法的策略在NewWithError中返回ErrInvalidOption
+func WithSpawnStrategy(strategy SpawnStrategy) Option {
+	return func(p *Pool) {
+		p.spawnStrategy = strategy
+	}
+}
+
+// keepFull 返回run是否需要主动将worker数量保持在容量，按提交顺序开始执行时同样需要
+func (p *Pool) keepFull() bool {
+	return p.spawnStrategy == SpawnEager || p.orderedStart
+}
+
+// FreezeWorkers 冻结worker的创建，存活的worker继续处理任务，但不再创建新的worker，比Tune更温和
+// 冻结期间容量不变，已退出的worker也不会被补充；存活worker为0时任务会一直等待到ThawWorkers
+// 冻结期间WithRampUp错开创建的worker被跳过
+func (p *Pool) FreezeWorkers() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.frozen.Store(true)
+	p.notify()
+}
+
+// ThawWorkers 解除FreezeWorkers的冻结，run按创建策略恢复创建worker
+func (p *Pool) ThawWorkers() {
+	if !p.isInitialized() {
+		return
+	}
+
+	p.frozen.Store(false)
+	p.notify()
+}
@@ -0,0 +1,54 @@
+package main
+
+// State 定义工作池的运行状态
+type State int32
+
+const (
+	StateRunning      State = iota // 运行中，正常接收任务
+	StateShuttingDown              // 正在关闭，不再接收任务，等待worker退出
+	StateFreed                     // 已释放，所有worker均已退出
+	StateResetting                 // 正在通过Reset重新初始化，不接收任务
+)
+
+// String 返回状态的可读名称
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateShuttingDown:
+		return "shutting-down"
+	case StateFreed:
+		return "freed"
+	case StateResetting:
+		return "resetting"
+	default:
+		return "unknown"
+	}
+}
+
+// State 返回工作池当前的运行状态，可以与Free/Shutdown并发调用；工作池未初始化时返回StateFreed
+func (p *Pool) State() State {
+	if !p.isInitialized() {
+		return StateFreed
+	}
+	return State(p.state.Load())
+}
+
+// IsFreed 返回工作池是否已开始释放，为true时Schedule等提交方法会返回ErrWorkerPoolFreed
+func (p *Pool) IsFreed() bool {
+	return p.State() != StateRunning
+}
+
+// Healthy 返回工作池是否处于健康状态，可用于就绪探针
+// 工作池已开始释放、正在排空，或者有任务排队却没有任何存活的worker（例如容量被调整为0）时返回false
+// 按需创建worker的短暂间隙中也可能观察到没有存活worker，调用方应当在连续多次返回false后再判定工作池失效
+func (p *Pool) Healthy() bool {
+	if !p.isInitialized() {
+		return false
+	}
+	if p.IsFreed() || p.draining.Load() {
+		return false
+	}
+
+	return p.workers.Load() > 0 || p.queueLen() == 0
+}
@@ -0,0 +1,83 @@
+package main
+
+// PoolStats 工作池运行指标的快照
+type PoolStats struct {
+	Capacity  int    // 工作池容量
+	Running   int    // 正在执行任务的worker数量
+	Free      int    // 空闲的容量，即Capacity减去Running
+	QueueLen  int    // 排队等待执行的任务数量，包括优先级任务
+	Submitted uint64 // 累计成功提交的任务数量
+	Completed uint64 // 累计执行完成的任务数量
+	Panics    uint64 // 累计发生panic的任务数量
+	Rejected  uint64 // 累计因容量不足被拒绝或丢弃的任务数量
+}
+
+// Stats 返回工作池运行指标的快照，各字段分别原子读取，整体上近似一致
+func (p *Pool) Stats() PoolStats {
+	if !p.isInitialized() {
+		return PoolStats{}
+	}
+
+	capacity, running := p.Cap(), p.Running()
+
+	return PoolStats{
+		Capacity:  capacity,
+		Running:   running,
+		Free:      capacity - running,
+		QueueLen:  p.queueLen(),
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Panics:    p.panics.Load(),
+		Rejected:  p.rejected.Load(),
+	}
+}
+
+// queueLen 返回排队等待执行的任务数量
+func (p *Pool) queueLen() int {
+	p.prioMu.Lock()
+	n := p.prioLen()
+	p.prioMu.Unlock()
+
+	return n + p.QueueLen()
+}
+
+// WorkerStats 返回当前存活的每个worker累计执行完成的任务数量，键为worker编号
+// worker退出后不再统计，编号被新worker复用时从0开始计数；释放工作池时兜底执行的任务不计入任何worker
+func (p *Pool) WorkerStats() map[int]uint64 {
+	if !p.isInitialized() {
+		return nil
+	}
+
+	p.statesMu.Lock()
+	defer p.statesMu.Unlock()
+
+	stats := make(map[int]uint64, len(p.states))
+	for id, ws := range p.states {
+		stats[id] = ws.handled.Load()
+	}
+	return stats
+}
+
+// registerWorker 登记一个新启动的worker，返回该worker的状态
+func (p *Pool) registerWorker(id int) *workerState {
+	ws := newWorkerState(id)
+
+	p.statesMu.Lock()
+	if p.states == nil {
+		p.states = make(map[int]*workerState)
+	}
+	p.states[id] = ws
+	p.statesMu.Unlock()
+
+	return ws
+}
+
+// unregisterWorker 移除一个退出的worker的状态
+func (p *Pool) unregisterWorker(id int) {
+	p.statesMu.Lock()
+	ws := p.states[id]
+	delete(p.states, id)
+	p.statesMu.Unlock()
+
+	ws.exit()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// TypedPool 定义返回固定类型结果的泛型工作池，复用Pool的worker调度机制
+type TypedPool[T any] struct {
+	*Pool
+}
+
+// Result 表示一个泛型任务的执行结果
+type Result[T any] struct {
+	done  chan struct{} // 任务完成后关闭
+	value T             // 任务返回值
+	err   error         // 任务返回的错误，任务panic时为包装了ErrTaskPanic的错误
+	clock Clock         // 提交任务的工作池的时钟，用于GetTimeout计时
+}
+
+// NewTyped 创建一个新的泛型工作池实例，参数含义与New一致
+func NewTyped[T any](capacity int, opts ...Option) *TypedPool[T] {
+	return &TypedPool[T]{Pool: New(capacity, opts...)}
+}
+
+// Submit 提交一个带返回值的任务到工作池执行，返回用于获取结果的Result
+// 工作池未通过NewTyped创建时返回ErrPoolNotInitialized
+func (tp *TypedPool[T]) Submit(fn func() (T, error)) (*Result[T], error) {
+	if tp == nil || !tp.isInitialized() {
+		return nil, ErrPoolNotInitialized
+	}
+
+	r := &Result[T]{done: make(chan struct{}), clock: tp.clock}
+
+	err := tp.Schedule(func() {
+		defer func() {
+			if v := recover(); v != nil {
+				r.err = fmt.Errorf("%w: %v", ErrTaskPanic, v)
+			}
+			close(r.done)
+		}()
+
+		r.value, r.err = fn()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Get 阻塞等待任务完成并返回其结果
+func (r *Result[T]) Get() (T, error) {
+	<-r.done
+	return r.value, r.err
+}
+
+// GetTimeout 等待任务完成并返回其结果，超过d仍未完成时返回T的零值和ErrResultTimeout
+// 超时只是放弃等待，任务仍在worker中继续执行，之后可以再次调用Get获取结果
+func (r *Result[T]) GetTimeout(d time.Duration) (T, error) {
+	timer := r.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		var zero T
+		return zero, ErrResultTimeout
+	case <-r.done:
+		return r.value, r.err
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import "fmt"
+
+// ScheduleWeighted 提交一个加权任务到工作池执行，权重为weight的任务在执行期间占用weight个并发单位
+// 普通任务占用1个单位，正在执行的任务占用的单位总数不超过容量，因此容量为10的工作池同时执行的重任务更少
+// weight必须在[1, 容量]范围内，否则返回ErrInvalidWeight；weight为1时与Schedule相同
+// 加权任务被worker取出后，如果剩余单位不足，会在该worker上等待其他任务释放单位后再开始执行
+// 工作池未通过New等构造函数创建时返回ErrPoolNotInitialized
+func (p *Pool) ScheduleWeighted(t Task, weight int) error {
+	if !p.isInitialized() {
+		return ErrPoolNotInitialized
+	}
+	if weight < 1 || weight > p.Cap() {
+		return fmt.Errorf("%w: %d", ErrInvalidWeight, weight)
+	}
+	if weight == 1 {
+		return p.Schedule(t)
+	}
+
+	extra := int64(weight - 1)
+	return p.Schedule(func() {
+		p.acquireWeight(extra)
+		defer p.releaseWeight(extra)
+
+		t()
+	})
+}
+
+// acquireWeight 在加权任务开始执行前为其预留除所在worker之外的extra个单位，单位不足时等待
+// 等待中的加权任务虽然已计入running，但还没有开始执行，检查时其他等待者不计入占用的单位，避免它们互相等待而死锁
+// 工作池被释放时不再等待，直接执行任务
+func (p *Pool) acquireWeight(extra int64) {
+	p.availWaiters.Add(1)
+	defer p.availWaiters.Add(-1)
+
+	p.availMu.Lock()
+	p.weightWaiting++
+	p.availMu.Unlock()
+
+	for {
+		p.availMu.Lock()
+		// 减去的等待者包括自己，因此加回自己所在worker的1个单位
+		used := p.running.Load() - p.weightWaiting + 1 + p.extraWeight.Load()
+		if used+extra <= p.capacity.Load() {
+			p.weightWaiting--
+			p.extraWeight.Add(extra)
+			p.availMu.Unlock()
+			return
+		}
+		if p.avail == nil {
+			p.avail = make(chan struct{})
+		}
+		avail := p.avail
+		p.availMu.Unlock()
+
+		select {
+		case <-avail:
+		case <-p.quit:
+			p.availMu.Lock()
+			p.weightWaiting--
+			p.extraWeight.Add(extra)
+			p.availMu.Unlock()
+			return
+		}
+	}
+}
+
+// releaseWeight 在加权任务执行结束后释放其预留的单位，并唤醒等待单位的worker
+func (p *Pool) releaseWeight(extra int64) {
+	p.extraWeight.Add(-extra)
+	p.wakeAvailable()
+}
+
+// weightGate 在有加权任务执行时检查是否还有剩余的单位可以执行新任务
+// 没有剩余单位时返回一个在有单位释放后关闭的通道，worker在此期间不再从队列中取任务
+func (p *Pool) weightGate() <-chan struct{} {
+	if p.extraWeight.Load() == 0 {
+		return nil
+	}
+
+	p.availMu.Lock()
+	defer p.availMu.Unlock()
+
+	if p.running.Load()+p.extraWeight.Load() < p.capacity.Load() {
+		return nil
+	}
+	if p.avail == nil {
+		p.avail = make(chan struct{})
+	}
+	return p.avail
+}
+
+// waitUnits 等待加权任务释放出可用的单位，用于worker创建时携带的第一个任务
+// 工作池被释放时不再等待
+func (p *Pool) waitUnits() {
+	for gate := p.weightGate(); gate != nil; gate = p.weightGate() {
+		select {
+		case <-gate:
+		case <-p.quit:
+			return
+		}
+	}
+}
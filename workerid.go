@@ -0,0 +1,33 @@
+package main
+
+import "sync"
+
+// workerIDs 管理可复用的worker编号，worker退出时归还编号，由下一个创建的worker复用
+// 存活worker的编号互不相同，且不超过工作池曾经设置过的最大容量
+type workerIDs struct {
+	mu   sync.Mutex
+	next int   // 尚未分配过的最小编号减一
+	free []int // 已归还可复用的编号
+}
+
+// get 分配一个编号，优先复用已归还的编号
+func (w *workerIDs) get() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(w.free); n > 0 {
+		id := w.free[n-1]
+		w.free = w.free[:n-1]
+		return id
+	}
+
+	w.next++
+	return w.next
+}
+
+// put 归还一个编号
+func (w *workerIDs) put(id int) {
+	w.mu.Lock()
+	w.free = append(w.free, id)
+	w.mu.Unlock()
+}